@@ -0,0 +1,239 @@
+// Package api serves tracking number identification over HTTP as JSON.
+//
+// The routes are:
+//
+//	GET  /v1/track/{number}  identify a single tracking number
+//	POST /v1/find            extract tracking numbers from a text body
+//	POST /v1/track:batch     identify a list of tracking numbers
+//	GET  /v1/openapi.json    the OpenAPI document for these routes
+//
+// Matches are encoded as parcel.Tracking, described by parcel.JSONSchema.
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+const (
+	// DefaultMaxBody is the largest request body accepted by default.
+	DefaultMaxBody = 1 << 20
+
+	// DefaultMaxBatch is the most numbers accepted in a batch by default.
+	DefaultMaxBatch = 1000
+)
+
+// TrackResponse is the result of identifying a single number.
+type TrackResponse struct {
+	Number  string            `json:"number" doc:"The number as requested."`
+	Matches []parcel.Tracking `json:"matches" doc:"Matching services, empty if the number isn't recognized."`
+	Error   string            `json:"error,omitempty" doc:"Set in batch results if the number couldn't be checked."`
+}
+
+// FindResponse is the result of extracting numbers from text.
+type FindResponse struct {
+	Results []FindResult `json:"results" doc:"Found numbers, sorted by term."`
+}
+
+// FindResult is a term found in the text and its matches.
+type FindResult struct {
+	Term    string            `json:"term" doc:"The term as it appeared in the text."`
+	Matches []parcel.Tracking `json:"matches"`
+}
+
+// BatchRequest is a list of numbers to identify.
+type BatchRequest struct {
+	Numbers []string `json:"numbers"`
+}
+
+// BatchResponse holds a result for each requested number, in order.
+type BatchResponse struct {
+	Results []TrackResponse `json:"results"`
+}
+
+// Error is the body of every error response.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// Config configures a Handler. The zero value is ready to use.
+type Config struct {
+	// MaxBody limits request bodies. Defaults to DefaultMaxBody.
+	MaxBody int64
+
+	// MaxBatch limits the numbers in a batch request. Defaults to
+	// DefaultMaxBatch.
+	MaxBatch int
+
+	// ErrorLog receives errors writing responses. Defaults to the standard
+	// logger.
+	ErrorLog *log.Logger
+}
+
+// Handler is an http.Handler serving the API routes.
+type Handler struct {
+	cfg Config
+}
+
+// NewHandler returns a handler for cfg.
+func NewHandler(cfg Config) *Handler {
+	if cfg.MaxBody <= 0 {
+		cfg.MaxBody = DefaultMaxBody
+	}
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = DefaultMaxBatch
+	}
+	if cfg.ErrorLog == nil {
+		cfg.ErrorLog = log.Default()
+	}
+
+	return &Handler{cfg: cfg}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var allow []string
+	for _, rt := range routes {
+		params, ok := rt.match(r.URL.Path)
+		if !ok {
+			continue
+		}
+		if rt.Method != r.Method {
+			allow = append(allow, rt.Method)
+			continue
+		}
+		rt.handle(h, w, r, params)
+		return
+	}
+
+	if len(allow) > 0 {
+		w.Header().Set("Allow", strings.Join(allow, ", "))
+		h.error(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	h.error(w, http.StatusNotFound, "not found")
+}
+
+func (h *Handler) track(w http.ResponseWriter, r *http.Request, params map[string]string) {
+	res, err := h.trackOne(params["number"])
+	if err != nil {
+		h.error(w, status(err), err.Error())
+		return
+	}
+
+	h.write(w, http.StatusOK, res)
+}
+
+func (h *Handler) trackOne(number string) (TrackResponse, error) {
+	res, err := parcel.Track(number)
+	if err != nil {
+		return TrackResponse{Number: number, Matches: []parcel.Tracking{}}, err
+	}
+	if res == nil {
+		res = []parcel.Tracking{}
+	}
+
+	return TrackResponse{Number: number, Matches: res}, nil
+}
+
+func (h *Handler) find(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	body, ok := h.body(w, r)
+	if !ok {
+		return
+	}
+
+	found, err := parcel.Find(string(body))
+	if err != nil {
+		h.error(w, status(err), err.Error())
+		return
+	}
+
+	res := FindResponse{Results: make([]FindResult, 0, len(found))}
+	for term, matches := range found {
+		res.Results = append(res.Results, FindResult{Term: term, Matches: matches})
+	}
+	sort.Slice(res.Results, func(i, j int) bool {
+		return res.Results[i].Term < res.Results[j].Term
+	})
+
+	h.write(w, http.StatusOK, res)
+}
+
+func (h *Handler) batch(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	body, ok := h.body(w, r)
+	if !ok {
+		return
+	}
+
+	var req BatchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		h.error(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if len(req.Numbers) > h.cfg.MaxBatch {
+		h.error(w, http.StatusRequestEntityTooLarge, "too many numbers")
+		return
+	}
+
+	res := BatchResponse{Results: make([]TrackResponse, 0, len(req.Numbers))}
+	for _, n := range req.Numbers {
+		tr, err := h.trackOne(n)
+		if errors.Is(err, parcel.ErrNoServices) {
+			h.error(w, status(err), err.Error())
+			return
+		} else if err != nil {
+			tr.Error = err.Error()
+		}
+		res.Results = append(res.Results, tr)
+	}
+
+	h.write(w, http.StatusOK, res)
+}
+
+func (h *Handler) openAPI(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+	h.write(w, http.StatusOK, OpenAPI())
+}
+
+// body reads a request body within the size limit. It writes an error
+// response and returns false if it can't.
+func (h *Handler) body(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.cfg.MaxBody+1))
+	if err != nil {
+		h.error(w, http.StatusBadRequest, "reading body")
+		return nil, false
+	}
+	if int64(len(body)) > h.cfg.MaxBody {
+		h.error(w, http.StatusRequestEntityTooLarge, "body too large")
+		return nil, false
+	}
+
+	return body, true
+}
+
+func (h *Handler) error(w http.ResponseWriter, code int, msg string) {
+	h.write(w, code, Error{Error: msg})
+}
+
+func (h *Handler) write(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.cfg.ErrorLog.Printf("api: writing response: %v", err)
+	}
+}
+
+// status returns the response status for a parcel error.
+func status(err error) int {
+	if errors.Is(err, parcel.ErrBadString) {
+		return http.StatusBadRequest
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,201 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/api"
+)
+
+func do(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func TestTrack(t *testing.T) {
+	h := api.NewHandler(api.Config{})
+
+	rec := do(h, http.MethodGet, "/v1/track/1Z5R89390357567127", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /v1/track status %d, expected %d", rec.Code, http.StatusOK)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET /v1/track Content-Type %q", ct)
+	}
+
+	var res api.TrackResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("json.Unmarshal() error %v", err)
+	}
+	if len(res.Matches) != 1 || res.Matches[0].Courier != "ups" || res.Matches[0].ServiceID != "ups" {
+		t.Errorf("GET /v1/track = %+v, expected one ups match", res)
+	}
+	if !strings.Contains(rec.Body.String(), `"tracking_number":"1Z5R89390357567127"`) {
+		t.Errorf("GET /v1/track body missing snake case fields\n%s", rec.Body.String())
+	}
+
+	rec = do(h, http.MethodGet, "/v1/track/nothing", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"matches":[]`) {
+		t.Errorf("GET /v1/track unknown = %d %s, expected empty matches", rec.Code, rec.Body.String())
+	}
+
+	rec = do(h, http.MethodGet, "/v1/track/%C3%A9t%C3%A9", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("GET /v1/track extended characters status %d, expected %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestFind(t *testing.T) {
+	h := api.NewHandler(api.Config{MaxBody: 64})
+
+	rec := do(h, http.MethodPost, "/v1/find", "order RB123456785GB and 1Z5R89390357567127")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /v1/find status %d, expected %d", rec.Code, http.StatusOK)
+	}
+
+	var res api.FindResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("json.Unmarshal() error %v", err)
+	}
+	if len(res.Results) != 2 || res.Results[0].Term != "1Z5R89390357567127" || res.Results[1].Term != "RB123456785GB" {
+		t.Errorf("POST /v1/find = %+v, expected two sorted results", res)
+	}
+
+	rec = do(h, http.MethodPost, "/v1/find", strings.Repeat("x", 65))
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST /v1/find large body status %d, expected %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	h := api.NewHandler(api.Config{MaxBatch: 3})
+
+	rec := do(h, http.MethodPost, "/v1/track:batch", `{"numbers":["986578788855","nope","été"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /v1/track:batch status %d, expected %d", rec.Code, http.StatusOK)
+	}
+
+	var res api.BatchResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatalf("json.Unmarshal() error %v", err)
+	}
+	if len(res.Results) != 3 {
+		t.Fatalf("POST /v1/track:batch returned %d results, expected 3", len(res.Results))
+	}
+	if got := len(res.Results[0].Matches); got != 2 {
+		t.Errorf("POST /v1/track:batch first number has %d matches, expected 2", got)
+	}
+	if got := len(res.Results[1].Matches); got != 0 || res.Results[1].Error != "" {
+		t.Errorf("POST /v1/track:batch second number = %+v, expected no matches", res.Results[1])
+	}
+	if res.Results[2].Error == "" {
+		t.Errorf("POST /v1/track:batch third number expected an error")
+	}
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", `{"numbers":`, http.StatusBadRequest},
+		{"too many", `{"numbers":["1","2","3","4"]}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := do(h, http.MethodPost, "/v1/track:batch", tt.body); rec.Code != tt.code {
+				t.Errorf("POST /v1/track:batch status %d, expected %d", rec.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestRouting(t *testing.T) {
+	h := api.NewHandler(api.Config{})
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+		allow  string
+	}{
+		{http.MethodPost, "/v1/track/1Z5R89390357567127", http.StatusMethodNotAllowed, "GET"},
+		{http.MethodGet, "/v1/find", http.StatusMethodNotAllowed, "POST"},
+		{http.MethodGet, "/v1/track/", http.StatusNotFound, ""},
+		{http.MethodGet, "/v2/track/1", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rec := do(h, tt.method, tt.path, "")
+		if rec.Code != tt.code {
+			t.Errorf("%s %s status %d, expected %d", tt.method, tt.path, rec.Code, tt.code)
+		}
+		if got := rec.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s Allow %q, expected %q", tt.method, tt.path, got, tt.allow)
+		}
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	rec := do(api.NewHandler(api.Config{}), http.MethodGet, "/v1/openapi.json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /v1/openapi.json status %d, expected %d", rec.Code, http.StatusOK)
+	}
+
+	var doc struct {
+		OpenAPI string                               `json:"openapi"`
+		Paths   map[string]map[string]map[string]any `json:"paths"`
+		Comps   struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]any `json:"properties"`
+				Required   []string                  `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("json.Unmarshal() error %v", err)
+	}
+
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+	for path, method := range map[string]string{
+		"/v1/track/{number}": "get",
+		"/v1/find":           "post",
+		"/v1/track:batch":    "post",
+	} {
+		if _, ok := doc.Paths[path][method]; !ok {
+			t.Errorf("OpenAPI missing %s %s", method, path)
+		}
+	}
+
+	tracking, ok := doc.Comps.Schemas["Tracking"]
+	if !ok {
+		t.Fatalf("OpenAPI missing Tracking schema")
+	}
+	if tracking.Properties["details"]["type"] != "object" {
+		t.Errorf("Tracking.details schema = %v", tracking.Properties["details"])
+	}
+	for _, f := range tracking.Required {
+		if f == "check_digit" {
+			t.Errorf("Tracking.check_digit is omitempty but required")
+		}
+	}
+
+	// The schema is parcel.JSONSchema, so it matches the encoded matches.
+	var want struct {
+		Required []string `json:"required"`
+	}
+	if err := json.Unmarshal(parcel.JSONSchema, &want); err != nil {
+		t.Fatalf("json.Unmarshal() error %v", err)
+	}
+	if !reflect.DeepEqual(tracking.Required, want.Required) {
+		t.Errorf("Tracking required = %v, expected %v", tracking.Required, want.Required)
+	}
+}
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+// route is an API route. The OpenAPI document is generated from the same
+// table the handler dispatches from.
+type route struct {
+	Method  string
+	Path    string // segments in braces are path parameters
+	Summary string
+
+	// Request is the request body type, nil for no body. A string means a
+	// text/plain body.
+	Request  any
+	Response any
+	Errors   []int
+
+	handle func(h *Handler, w http.ResponseWriter, r *http.Request, params map[string]string)
+}
+
+// routes is populated in init because the OpenAPI route refers back to it.
+var routes []route
+
+func init() {
+	routes = []route{
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/track/{number}",
+			Summary:  "Identify a tracking number",
+			Response: TrackResponse{},
+			Errors:   []int{http.StatusBadRequest},
+			handle:   (*Handler).track,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v1/find",
+			Summary:  "Extract tracking numbers from text",
+			Request:  "",
+			Response: FindResponse{},
+			Errors:   []int{http.StatusRequestEntityTooLarge},
+			handle:   (*Handler).find,
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v1/track:batch",
+			Summary:  "Identify a list of tracking numbers",
+			Request:  BatchRequest{},
+			Response: BatchResponse{},
+			Errors:   []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge},
+			handle:   (*Handler).batch,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/openapi.json",
+			Summary:  "This OpenAPI document",
+			Response: map[string]any{},
+			handle:   (*Handler).openAPI,
+		},
+	}
+}
+
+// match reports whether a request path matches the route and returns its
+// path parameters.
+func (rt route) match(path string) (map[string]string, bool) {
+	want := strings.Split(rt.Path, "/")
+	got := strings.Split(path, "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, seg := range want {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			if got[i] == "" {
+				return nil, false
+			}
+			params[seg[1:len(seg)-1]] = got[i]
+			continue
+		}
+		if seg != got[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+// OpenAPI returns the OpenAPI 3 document describing the API.
+func OpenAPI() map[string]any {
+	schemas := map[string]any{}
+	paths := map[string]any{}
+
+	for _, rt := range routes {
+		op := map[string]any{
+			"summary":     rt.Summary,
+			"operationId": operationID(rt),
+		}
+
+		var params []any
+		for _, seg := range strings.Split(rt.Path, "/") {
+			if strings.HasPrefix(seg, "{") {
+				params = append(params, map[string]any{
+					"name":     strings.Trim(seg, "{}"),
+					"in":       "path",
+					"required": true,
+					"schema":   map[string]any{"type": "string"},
+				})
+			}
+		}
+		if params != nil {
+			op["parameters"] = params
+		}
+
+		if rt.Request != nil {
+			content := "application/json"
+			if _, ok := rt.Request.(string); ok {
+				content = "text/plain"
+			}
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					content: map[string]any{"schema": schema(reflect.TypeOf(rt.Request), schemas)},
+				},
+			}
+		}
+
+		responses := map[string]any{
+			"200": response("OK", schema(reflect.TypeOf(rt.Response), schemas)),
+		}
+		for _, code := range append(rt.Errors, http.StatusInternalServerError) {
+			responses[strconv.Itoa(code)] = response(http.StatusText(code), schema(reflect.TypeOf(Error{}), schemas))
+		}
+		op["responses"] = responses
+
+		item, _ := paths[rt.Path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[rt.Path] = item
+		}
+		item[strings.ToLower(rt.Method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "parcel",
+			"version": "1",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+func response(desc string, s map[string]any) map[string]any {
+	return map[string]any{
+		"description": desc,
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": s},
+		},
+	}
+}
+
+// operationID derives an operation ID such as "postV1TrackBatch".
+func operationID(rt route) string {
+	id := strings.ToLower(rt.Method)
+	for _, part := range strings.FieldsFunc(rt.Path, func(r rune) bool {
+		return r == '/' || r == ':' || r == '.' || r == '{' || r == '}'
+	}) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return id
+}
+
+// trackingType is described by parcel.JSONSchema rather than by reflection,
+// since it encodes its own JSON.
+var trackingType = reflect.TypeOf(parcel.Tracking{})
+
+// trackingSchema returns parcel.JSONSchema without its document keywords.
+func trackingSchema() map[string]any {
+	var s map[string]any
+	if err := json.Unmarshal(parcel.JSONSchema, &s); err != nil {
+		panic(err)
+	}
+	delete(s, "$schema")
+	delete(s, "$id")
+
+	return s
+}
+
+// schema returns the JSON schema for a type. Named structs are added to
+// schemas and referenced.
+func schema(t reflect.Type, schemas map[string]any) map[string]any {
+	if t == trackingType {
+		schemas[t.Name()] = trackingSchema()
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schema(t.Elem(), schemas)
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schema(t.Elem(), schemas)}
+	case reflect.Map:
+		s := map[string]any{"type": "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			s["additionalProperties"] = schema(t.Elem(), schemas)
+		}
+		return s
+	case reflect.Struct:
+		ref := map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; ok {
+			return ref
+		}
+		schemas[t.Name()] = nil // placeholder for recursive types
+
+		props := map[string]any{}
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+
+			p := schema(f.Type, schemas)
+			if doc := f.Tag.Get("doc"); doc != "" {
+				if _, ok := p["$ref"]; ok {
+					p = map[string]any{"allOf": []any{p}}
+				}
+				p["description"] = doc
+			}
+			props[name] = p
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+
+		s := map[string]any{"type": "object", "properties": props}
+		if required != nil {
+			s["required"] = required
+		}
+		schemas[t.Name()] = s
+
+		return ref
+	}
+
+	return map[string]any{}
+}
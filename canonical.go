@@ -0,0 +1,126 @@
+package parcel
+
+import (
+	"sort"
+	"strings"
+)
+
+// paddedCouriers zero pad serial numbers in longer barcodes, such as the
+// 34 digit FedEx barcode holding a 12 digit tracking number.
+var paddedCouriers = map[string]bool{
+	"fedex": true,
+}
+
+// identityGroups are the details that tell apart shipments with the same
+// serial number and check digit, by service key.
+var identityGroups = map[string][]string{
+	"s10":             {"ServiceType", "CountryCode"},
+	"ups/UPS Waybill": {"ServiceType"},
+}
+
+// CanonicalID returns the identity of the shipment, the same for every
+// representation of its number: with or without spaces, in a longer
+// barcode or with a ZIP code prefix. It is the courier code followed by the
+// serial number and check digit, such as "fedex:779017972697".
+func (t Tracking) CanonicalID() string {
+	serial := t.SerialNumber
+	if paddedCouriers[t.Courier] {
+		serial = strings.TrimLeft(serial, "0")
+	}
+
+	var b strings.Builder
+	b.WriteString(t.Courier)
+	b.WriteByte(':')
+	for _, g := range identityGroups[t.ServiceKey()] {
+		b.WriteString(t.Details[g])
+	}
+	if serial == "" && t.CheckDigit == "" {
+		serial = t.number()
+	}
+	b.WriteString(serial)
+	b.WriteString(t.CheckDigit)
+
+	return b.String()
+}
+
+// Shipment is a shipment and the results representing it.
+type Shipment struct {
+	ID string // see Tracking.CanonicalID
+
+	// Tracking has a result per tracking number, shortest number first.
+	Tracking []Tracking
+}
+
+// Dedup collapses results representing the same shipment, such as a barcode
+// and the tracking number printed under it. A number matching several
+// services keeps only the services whose shipment other numbers share, if
+// any. Shipments are ordered by first appearance. Numbers are compared and
+// returned normalized, without the punctuation of the term they were found in.
+//
+// For Find results, pass the results of every number found.
+func Dedup(res []Tracking) []Shipment {
+	// Group the results by number and count the numbers supporting each
+	// shipment.
+	var numbers []string
+	byNumber := make(map[string][]Tracking)
+	support := make(map[string]map[string]bool)
+	for _, t := range res {
+		t.TrackingNumber = t.number()
+		if _, ok := byNumber[t.TrackingNumber]; !ok {
+			numbers = append(numbers, t.TrackingNumber)
+		}
+		byNumber[t.TrackingNumber] = append(byNumber[t.TrackingNumber], t)
+
+		id := t.CanonicalID()
+		if support[id] == nil {
+			support[id] = make(map[string]bool)
+		}
+		support[id][t.TrackingNumber] = true
+	}
+
+	var out []Shipment
+	index := make(map[string]int)
+	for _, num := range numbers {
+		best := 0
+		for _, t := range byNumber[num] {
+			if n := len(support[t.CanonicalID()]); n > best {
+				best = n
+			}
+		}
+
+		for _, t := range byNumber[num] {
+			id := t.CanonicalID()
+			if best > 1 && len(support[id]) < best {
+				continue
+			}
+
+			i, ok := index[id]
+			if !ok {
+				i = len(out)
+				index[id] = i
+				out = append(out, Shipment{ID: id})
+			}
+			if !hasNumber(out[i].Tracking, num) {
+				out[i].Tracking = append(out[i].Tracking, t)
+			}
+		}
+	}
+
+	for _, s := range out {
+		sort.SliceStable(s.Tracking, func(i, j int) bool {
+			return len(s.Tracking[i].TrackingNumber) < len(s.Tracking[j].TrackingNumber)
+		})
+	}
+
+	return out
+}
+
+func hasNumber(res []Tracking, num string) bool {
+	for _, t := range res {
+		if t.TrackingNumber == num {
+			return true
+		}
+	}
+
+	return false
+}
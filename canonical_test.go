@@ -0,0 +1,82 @@
+package parcel_test
+
+import (
+	"reflect"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+func TestCanonicalID(t *testing.T) {
+	tests := []struct {
+		name    string
+		numbers []string
+		want    string
+	}{
+		{"fedex barcode", []string{"1001921334250001000300779017972697", "779017972697", "7790 1797 2697"}, "fedex:779017972697"},
+		{"fedex gsn barcode", []string{"9622001900000000000000776632517510", "776632517510"}, "fedex:776632517510"},
+		{"fedex ground 96", []string{"9611020987654312345672", "987654312345672"}, "fedex:987654312345672"},
+		{"usps zip prefix", []string{"420 22153 9101026837331000039521", "9101026837331000039521"}, "usps:9101026837331000039521"},
+		{"usps routing", []string{"4201002334249200190132607600833457", "9200190132607600833457"}, "usps:9200190132607600833457"},
+		{"s10", []string{"RB123456785GB"}, "s10:RBGB123456785"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, num := range tt.numbers {
+				res, err := parcel.Track(num)
+				if err != nil {
+					t.Fatalf("parcel.Track() error %v", err)
+				}
+				var ids []string
+				for _, r := range res {
+					if ids = append(ids, r.CanonicalID()); r.CanonicalID() == tt.want {
+						break
+					}
+				}
+				if len(ids) == 0 || ids[len(ids)-1] != tt.want {
+					t.Errorf("Tracking.CanonicalID() of %s = %v, expected %s", num, ids, tt.want)
+				}
+			}
+		})
+	}
+
+	// Shipments only differing by country or service type stay apart.
+	a, _ := parcel.Track("RB123456785GB")
+	b, _ := parcel.Track("RB123456785US")
+	if a[0].CanonicalID() == b[0].CanonicalID() {
+		t.Errorf("Tracking.CanonicalID() = %s for both countries", a[0].CanonicalID())
+	}
+}
+
+func TestDedup(t *testing.T) {
+	found, err := parcel.Find(`FedEx barcode 9622001900000000000000776632517510 and label 776632517510,
+		USPS 420221539101026837331000039521 or 9101026837331000039521,
+		UPS 1Z5R89390357567127 and DHL 986578788855`)
+	if err != nil {
+		t.Fatalf("parcel.Find() error %v", err)
+	}
+	var res []parcel.Tracking
+	for _, r := range found {
+		res = append(res, r...)
+	}
+
+	got := make(map[string][]string)
+	for _, s := range parcel.Dedup(res) {
+		for _, r := range s.Tracking {
+			got[s.ID] = append(got[s.ID], r.TrackingNumber)
+		}
+	}
+
+	want := map[string][]string{
+		"fedex:776632517510":          {"776632517510", "9622001900000000000000776632517510"},
+		"usps:9101026837331000039521": {"9101026837331000039521", "420221539101026837331000039521"},
+		"ups:5R89390357567127":        {"1Z5R89390357567127"},
+		// A number without corroboration keeps every interpretation.
+		"dhl:98657878885":    {"986578788855"},
+		"fedex:986578788855": {"986578788855"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parcel.Dedup() = %v, expected %v", got, want)
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+// Batch formats.
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+)
+
+// batchColumns are appended to each batch row. Multiple matches are joined
+// with batchSep.
+var batchColumns = []string{"courier", "service", "tracking_url", "valid"}
+
+const batchSep = "|"
+
+// batchRecord is a row waiting to be classified.
+type batchRecord struct {
+	number string
+	csv    []string
+	json   map[string]json.RawMessage
+	done   chan []parcel.Tracking
+}
+
+// batchSummary counts the classified rows.
+type batchSummary struct {
+	total     int
+	invalid   int
+	ambiguous int
+	couriers  map[string]int
+}
+
+func (s *batchSummary) add(res []parcel.Tracking) {
+	s.total++
+	switch {
+	case len(res) == 0:
+		s.invalid++
+	case len(res) > 1:
+		s.ambiguous++
+	}
+
+	seen := map[string]bool{}
+	for _, t := range res {
+		if !seen[t.Courier] {
+			seen[t.Courier] = true
+			s.couriers[t.Courier]++
+		}
+	}
+}
+
+func (s *batchSummary) write(w io.Writer) error {
+	couriers := make([]string, 0, len(s.couriers))
+	for k := range s.couriers {
+		couriers = append(couriers, k)
+	}
+	sort.Strings(couriers)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "COURIER\tROWS")
+	for _, k := range couriers {
+		fmt.Fprintf(tw, "%s\t%d\n", k, s.couriers[k])
+	}
+	fmt.Fprintf(tw, "(ambiguous)\t%d\n", s.ambiguous)
+	fmt.Fprintf(tw, "(invalid)\t%d\n", s.invalid)
+	fmt.Fprintf(tw, "(total)\t%d\n", s.total)
+
+	return tw.Flush()
+}
+
+// runBatch classifies a column of tracking numbers in a CSV or JSONL file.
+// Rows are written in input order with the batchColumns appended and a
+// summary is written to stderr. Rows that don't match are not an error.
+func runBatch(e *env, args []string) int {
+	fs := flagSet(e, "batch")
+	in := fs.String("in", "-", "input file, - for stdin")
+	outName := fs.String("out", "-", "output file, - for stdout")
+	column := fs.String("column", "tracking", "column or field holding the tracking number")
+	format := fs.String("format", "", "csv or jsonl, detected from the input name if empty")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of rows classified in parallel")
+	quiet := fs.Bool("q", false, "don't write the summary")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 0 || *workers < 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	if *format == "" {
+		*format = formatCSV
+		switch strings.ToLower(filepath.Ext(*in)) {
+		case ".jsonl", ".ndjson":
+			*format = formatJSONL
+		}
+	}
+	if *format != formatCSV && *format != formatJSONL {
+		fmt.Fprintf(e.stderr, "parcel: unknown format %q\n", *format)
+		return exitError
+	}
+
+	var r io.Reader = e.stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+			return exitError
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var w io.Writer = e.stdout
+	var out *os.File
+	if *outName != "-" {
+		f, err := os.Create(*outName)
+		if err != nil {
+			fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+			return exitError
+		}
+		out, w = f, f
+	}
+
+	b := &batch{column: *column, workers: *workers, summary: batchSummary{couriers: map[string]int{}}}
+	var err error
+	if *format == formatJSONL {
+		err = b.jsonl(r, w)
+	} else {
+		err = b.csv(r, w)
+	}
+	// Closing flushes the output file, so its error is a write error too.
+	if out != nil {
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	if !*quiet {
+		if err := b.summary.write(e.stderr); err != nil {
+			fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+			return exitError
+		}
+	}
+
+	return exitMatch
+}
+
+// batch streams records through a bounded pool of workers, keeping the
+// output in input order.
+type batch struct {
+	column  string
+	workers int
+	summary batchSummary
+}
+
+// run classifies the records produced by read and passes each to write in
+// input order. read sends records until the input is exhausted or fails.
+func (b *batch) run(read func(emit func(*batchRecord)) error, write func(*batchRecord, []parcel.Tracking) error) error {
+	jobs := make(chan *batchRecord)
+	order := make(chan *batchRecord, b.workers*2)
+
+	for i := 0; i < b.workers; i++ {
+		go func() {
+			for rec := range jobs {
+				res, err := parcel.Track(rec.number)
+				if err != nil || strings.TrimSpace(rec.number) == "" {
+					res = nil
+				}
+				rec.done <- res
+			}
+		}()
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		defer close(order)
+		readErr <- read(func(rec *batchRecord) {
+			rec.done = make(chan []parcel.Tracking, 1)
+			order <- rec
+			jobs <- rec
+		})
+	}()
+
+	var writeErr error
+	for rec := range order {
+		res := <-rec.done
+		if writeErr != nil {
+			continue // drain so the reader and workers can finish
+		}
+		b.summary.add(res)
+		writeErr = write(rec, res)
+	}
+
+	if err := <-readErr; err != nil {
+		return err
+	}
+
+	return writeErr
+}
+
+// columns returns the values appended for a row.
+func columns(res []parcel.Tracking) []string {
+	var couriers, services, urls []string
+	for _, t := range res {
+		couriers = append(couriers, t.Courier)
+		services = append(services, t.ServiceKey())
+		urls = append(urls, t.TrackingURL)
+	}
+
+	return []string{
+		strings.Join(couriers, batchSep),
+		strings.Join(services, batchSep),
+		strings.Join(urls, batchSep),
+		strconv.FormatBool(len(res) > 0),
+	}
+}
+
+func (b *batch) csv(r io.Reader, w io.Writer) error {
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return errors.New("empty input")
+	} else if err != nil {
+		return err
+	}
+
+	col := -1
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), b.column) {
+			col = i
+			break
+		}
+	}
+	if col < 0 {
+		return fmt.Errorf("column %q not found", b.column)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append(header, batchColumns...)); err != nil {
+		return err
+	}
+
+	err = b.run(func(emit func(*batchRecord)) error {
+		for {
+			row, err := cr.Read()
+			if errors.Is(err, io.EOF) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			emit(&batchRecord{number: row[col], csv: row})
+		}
+	}, func(rec *batchRecord, res []parcel.Tracking) error {
+		return cw.Write(append(rec.csv, columns(res)...))
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (b *batch) jsonl(r io.Reader, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	err := b.run(func(emit func(*batchRecord)) error {
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 1024*1024)
+		for line := 1; sc.Scan(); line++ {
+			if strings.TrimSpace(sc.Text()) == "" {
+				continue
+			}
+
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(sc.Bytes(), &obj); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+			if obj == nil {
+				return fmt.Errorf("line %d: expected object", line)
+			}
+
+			emit(&batchRecord{number: jsonNumber(obj[b.column]), json: obj})
+		}
+		return sc.Err()
+	}, func(rec *batchRecord, res []parcel.Tracking) error {
+		vals := columns(res)
+		for i, k := range batchColumns[:len(batchColumns)-1] {
+			rec.json[k], _ = json.Marshal(vals[i])
+		}
+		rec.json["valid"], _ = json.Marshal(len(res) > 0)
+
+		line, err := json.Marshal(rec.json)
+		if err != nil {
+			return err
+		}
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+		return bw.WriteByte('\n')
+	})
+	if err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// jsonNumber returns the tracking number held by a JSONL field. Numbers
+// written as JSON numbers are kept as written; other values, such as null or
+// an object, hold no number and leave the row invalid.
+func jsonNumber(raw json.RawMessage) string {
+	var v any
+	d := json.NewDecoder(bytes.NewReader(raw))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return ""
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+
+	return ""
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		want   string
+		stderr []string
+	}{
+		{
+			name:  "csv",
+			args:  []string{"batch", "-workers", "3"},
+			stdin: "id,Tracking\n1,1Z5R89390357567127\n2,nope\n3,986578788855\n4,\n",
+			code:  exitMatch,
+			want: "id,Tracking,courier,service,tracking_url,valid\n" +
+				"1,1Z5R89390357567127,ups,ups,https://wwwapps.ups.com/WebTracking/track?track=yes&trackNums=1Z5R89390357567127,true\n" +
+				"2,nope,,,,false\n" +
+				"3,986578788855,dhl|fedex,dhl_express|fedex_12,http://www.dhl.com/en/express/tracking.html?brand=DHL&AWB=986578788855|https://www.fedex.com/apps/fedextrack/?tracknumbers=986578788855,true\n" +
+				"4,,,,,false\n",
+			stderr: []string{"ups          1", "(ambiguous)  1", "(invalid)    2", "(total)      4"},
+		},
+		{
+			name:  "jsonl",
+			args:  []string{"batch", "-format", "jsonl", "-column", "num", "-q"},
+			stdin: "{\"num\":\"RB123456785GB\",\"n\":1}\n\n{\"num\":\"zz\"}\n",
+			code:  exitMatch,
+			want: `{"courier":"s10","n":1,"num":"RB123456785GB","service":"s10","tracking_url":"","valid":true}` + "\n" +
+				`{"courier":"","num":"zz","service":"","tracking_url":"","valid":false}` + "\n",
+		},
+		{
+			name:  "jsonl numbers",
+			args:  []string{"batch", "-format", "jsonl", "-column", "num", "-q"},
+			stdin: "{\"num\":986578788855}\n{\"num\":{\"a\":1}}\n{\"num\":\"zz\"}\n",
+			code:  exitMatch,
+			want: `{"courier":"dhl|fedex","num":986578788855,"service":"dhl_express|fedex_12","tracking_url":"http://www.dhl.com/en/express/tracking.html?brand=DHL\u0026AWB=986578788855|https://www.fedex.com/apps/fedextrack/?tracknumbers=986578788855","valid":true}` + "\n" +
+				`{"courier":"","num":{"a":1},"service":"","tracking_url":"","valid":false}` + "\n" +
+				`{"courier":"","num":"zz","service":"","tracking_url":"","valid":false}` + "\n",
+		},
+		{
+			name:   "missing column",
+			args:   []string{"batch", "-column", "number"},
+			stdin:  "id,tracking\n1,2\n",
+			code:   exitError,
+			stderr: []string{`column "number" not found`},
+		},
+		{
+			name:   "bad jsonl",
+			args:   []string{"batch", "-format", "jsonl"},
+			stdin:  "{\"tracking\":\"1\"}\nnot json\n",
+			code:   exitError,
+			stderr: []string{"line 2"},
+		},
+		{
+			name:   "jsonl not an object",
+			args:   []string{"batch", "-format", "jsonl"},
+			stdin:  "{\"tracking\":\"1Z5R89390357567127\"}\nnull\n",
+			code:   exitError,
+			stderr: []string{"line 2: expected object"},
+		},
+		{
+			name:  "service without id",
+			args:  []string{"batch", "-q"},
+			stdin: "tracking\nK1506235620\n",
+			code:  exitMatch,
+			want: "tracking,courier,service,tracking_url,valid\n" +
+				"K1506235620,ups,ups/UPS Waybill,https://wwwapps.ups.com/WebTracking/track?track=yes&trackNums=K1506235620,true\n",
+		},
+		{
+			name:   "unknown format",
+			args:   []string{"batch", "-format", "xml"},
+			code:   exitError,
+			stderr: []string{`unknown format "xml"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			e := &env{stdin: strings.NewReader(tt.stdin), stdout: &stdout, stderr: &stderr}
+
+			if code := run(e, tt.args); code != tt.code {
+				t.Errorf("run() exit code %d, expected %d\n%s", code, tt.code, stderr.String())
+			}
+			if tt.want != "" && stdout.String() != tt.want {
+				t.Errorf("run() output\n%s\nexpected\n%s", stdout.String(), tt.want)
+			}
+			for _, w := range tt.stderr {
+				if !strings.Contains(stderr.String(), w) {
+					t.Errorf("run() stderr missing %q\n%s", w, stderr.String())
+				}
+			}
+		})
+	}
+}
+
+func TestBatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.jsonl")
+	out := filepath.Join(dir, "out.jsonl")
+
+	var rows strings.Builder
+	for i := 0; i < 500; i++ {
+		rows.WriteString(`{"tracking":"1Z5R89390357567127"}` + "\n")
+	}
+	if err := os.WriteFile(in, []byte(rows.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	e := &env{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}
+	if code := run(e, []string{"batch", "-in", in, "-out", out}); code != exitMatch {
+		t.Fatalf("run() exit code %d, expected %d\n%s", code, exitMatch, stderr.String())
+	}
+
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(b), `"valid":true`); n != 500 {
+		t.Errorf("run() wrote %d valid rows, expected 500", n)
+	}
+	if !strings.Contains(stderr.String(), "(total)      500") {
+		t.Errorf("run() summary missing total\n%s", stderr.String())
+	}
+}
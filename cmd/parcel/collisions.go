@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+// runCollisions reports which services match the same generated numbers.
+func runCollisions(e *env, args []string) int {
+	fs, out := flags(e, "collisions")
+	n := fs.Int("n", 200, "valid numbers generated per service")
+	seed := fs.Int64("seed", 1, "random seed")
+	matrix := fs.Bool("matrix", false, "write the full matrix instead of the colliding pairs")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 0 || *n < 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	report := parcel.Collisions(*n, *seed)
+
+	var err error
+	if *matrix {
+		err = writeMatrix(out, report)
+	} else {
+		collisions := report.Collisions()
+		rows := make([][]string, 0, len(collisions))
+		for _, c := range collisions {
+			rate := fmt.Sprintf("%.0f%%", 100*float64(c.Matches)/float64(c.Samples))
+			rows = append(rows, []string{c.Service, c.Other, rate, c.Example, c.Winner})
+		}
+		err = out.write(collisions, []string{"SERVICE", "ALSO MATCHED BY", "RATE", "EXAMPLE", "WINNER"}, rows)
+	}
+	if err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	return exitMatch
+}
+
+// writeMatrix writes the collision counts. Tables number the columns to
+// keep them narrow; CSV uses the service keys.
+func writeMatrix(out *output, r parcel.CollisionReport) error {
+	header := []string{"SERVICE", "SAMPLES"}
+	for i, s := range r.Services {
+		if out.csv {
+			header = append(header, s)
+		} else {
+			header = append(header, strconv.Itoa(i+1))
+		}
+	}
+
+	rows := make([][]string, 0, len(r.Services))
+	for i, s := range r.Services {
+		label := s
+		if !out.csv {
+			label = strconv.Itoa(i+1) + " " + s
+		}
+		row := []string{label, strconv.Itoa(r.Samples[i])}
+		for _, n := range r.Matrix[i] {
+			if n == 0 && !out.csv {
+				row = append(row, ".")
+				continue
+			}
+			row = append(row, strconv.Itoa(n))
+		}
+		rows = append(rows, row)
+	}
+
+	return out.write(r, header, rows)
+}
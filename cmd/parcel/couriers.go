@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"dev.freespoke.com/go-package-tracking/internal"
+)
+
+// serviceSummary is a row of `parcel couriers list`.
+type serviceSummary struct {
+	Courier      string `json:"courier"`
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Checksum     string `json:"checksum"`
+	TrackingURL  string `json:"tracking_url"`
+	ValidTests   int    `json:"valid_tests"`
+	InvalidTests int    `json:"invalid_tests"`
+}
+
+// serviceDetail is the output of `parcel couriers show`.
+type serviceDetail struct {
+	serviceSummary
+	CourierName string                  `json:"courier_name"`
+	Description string                  `json:"description"`
+	Regex       string                  `json:"regex"`
+	PCRE        string                  `json:"pcre"`
+	Checksum    internal.CheckDigitOpts `json:"checksum"`
+	PrependIf   *prependDetail          `json:"prepend_if,omitempty"`
+	Exists      []string                `json:"exists,omitempty"`
+	Lookups     []lookupDetail          `json:"lookups,omitempty"`
+	Partners    []partnerDetail         `json:"partners,omitempty"`
+}
+
+type prependDetail struct {
+	Regex   string `json:"regex"`
+	PCRE    string `json:"pcre"`
+	Content string `json:"content"`
+}
+
+type lookupDetail struct {
+	Name    string `json:"name"`
+	Group   string `json:"group"`
+	Matches string `json:"matches"`
+	Value   string `json:"value"`
+}
+
+type partnerDetail struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+func summarize(s internal.Service) serviceSummary {
+	checksum := s.Validation.CheckDigitOpts.Name
+	if checksum == "" {
+		checksum = "none"
+	}
+
+	return serviceSummary{
+		Courier:      s.CourierCode,
+		ID:           s.ID,
+		Name:         s.Name,
+		Checksum:     checksum,
+		TrackingURL:  s.TrackingURL,
+		ValidTests:   len(s.TestNumbers.Valid),
+		InvalidTests: len(s.TestNumbers.Invalid),
+	}
+}
+
+func detail(s internal.Service) serviceDetail {
+	d := serviceDetail{
+		serviceSummary: summarize(s),
+		CourierName:    s.CourierName,
+		Description:    s.Description,
+		PCRE:           s.Regex.Source,
+		Checksum:       s.Validation.CheckDigitOpts,
+		Exists:         s.Validation.Additional.Exists,
+	}
+	d.Regex = s.Regex.String()
+
+	if p := s.Validation.SerialNumberFormat.PrependIf; p.Regex.Regex != nil {
+		d.PrependIf = &prependDetail{Regex: p.Regex.String(), PCRE: p.Regex.Source, Content: p.Content}
+	}
+
+	for _, a := range s.Additional {
+		for _, l := range a.Lookups {
+			matches := l.Matches
+			if matches == "" {
+				matches = "/" + l.MatchesRegex.Source + "/"
+			}
+			value := l.Name
+			if l.Country != "" {
+				value = l.Country
+				if l.Courier != "" {
+					value += " (" + l.Courier + ")"
+				}
+			}
+			d.Lookups = append(d.Lookups, lookupDetail{Name: a.Name, Group: a.RegexGroupName, Matches: matches, Value: value})
+		}
+	}
+
+	for _, p := range s.Partners {
+		d.Partners = append(d.Partners, partnerDetail{ID: p.PartnerID, Type: p.PartnerType, Description: p.Description})
+	}
+
+	return d
+}
+
+// runCouriers lists the loaded service definitions or shows one of them.
+func runCouriers(e *env, args []string) int {
+	fs, out := flags(e, "couriers")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	switch {
+	case fs.NArg() == 1 && fs.Arg(0) == "list":
+		return listCouriers(e, out)
+	case fs.NArg() == 2 && fs.Arg(0) == "show":
+		return showCourier(e, out, fs.Arg(1))
+	}
+
+	fs.Usage()
+	return exitError
+}
+
+func listCouriers(e *env, out *output) int {
+	res := make([]serviceSummary, 0, len(internal.Services))
+	rows := make([][]string, 0, len(internal.Services))
+	for _, s := range internal.Services {
+		sum := summarize(s)
+		res = append(res, sum)
+		rows = append(rows, []string{
+			sum.Courier, sum.ID, sum.Name, sum.Checksum, sum.TrackingURL,
+			strconv.Itoa(sum.ValidTests), strconv.Itoa(sum.InvalidTests),
+		})
+	}
+
+	header := []string{"COURIER", "ID", "NAME", "CHECKSUM", "URL", "VALID TESTS", "INVALID TESTS"}
+	if err := out.write(res, header, rows); err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	return exitMatch
+}
+
+// showCourier prints a service by ID, or by name for the services without
+// an ID.
+func showCourier(e *env, out *output, id string) int {
+	var found []serviceDetail
+	for _, s := range internal.Services {
+		if s.ID == id || (s.ID == "" && strings.EqualFold(s.Name, id)) {
+			found = append(found, detail(s))
+		}
+	}
+	if len(found) == 0 {
+		fmt.Fprintf(e.stderr, "parcel: no service %q\n", id)
+		return exitNoMatch
+	}
+
+	var err error
+	switch {
+	case out.json:
+		err = out.write(found, nil, nil)
+	case out.csv:
+		fmt.Fprintln(e.stderr, "parcel: -csv is not supported by couriers show")
+		return exitError
+	default:
+		for i, d := range found {
+			if i > 0 {
+				fmt.Fprintln(out.w)
+			}
+			if err = writeDetail(out.w, d); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	return exitCode(len(found))
+}
+
+func writeDetail(w io.Writer, d serviceDetail) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	field := func(k, v string) {
+		if v != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", k, v)
+		}
+	}
+
+	field("Courier", d.Courier+" ("+d.CourierName+")")
+	field("ID", d.ID)
+	field("Name", d.Name)
+	field("Description", d.Description)
+	field("Regex", d.Regex)
+	if d.PCRE != d.Regex {
+		field("PCRE", d.PCRE)
+	}
+	field("Checksum", d.serviceSummary.Checksum)
+	if c := d.Checksum; c.EvensMultiplier != 0 || c.OddsMultiplier != 0 {
+		field("Multipliers", fmt.Sprintf("evens %d, odds %d", c.EvensMultiplier, c.OddsMultiplier))
+	}
+	if c := d.Checksum; len(c.Weightings) != 0 {
+		field("Weightings", strings.Trim(fmt.Sprint(c.Weightings), "[]"))
+	}
+	if c := d.Checksum; c.Modulo1 != 0 {
+		field("Modulo", fmt.Sprintf("%d, %d", c.Modulo1, c.Modulo2))
+	}
+	if p := d.PrependIf; p != nil {
+		field("Prepend", fmt.Sprintf("%q if /%s/", p.Content, p.PCRE))
+	}
+	field("Exists", strings.Join(d.Exists, ", "))
+	field("URL", d.TrackingURL)
+	field("Tests", fmt.Sprintf("%d valid, %d invalid", d.ValidTests, d.InvalidTests))
+	for _, p := range d.Partners {
+		field("Partner", fmt.Sprintf("%s (%s) %s", p.ID, p.Type, p.Description))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(d.Lookups) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOOKUP\tGROUP\tMATCHES\tVALUE")
+	for _, l := range d.Lookups {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", l.Name, l.Group, l.Matches, l.Value)
+	}
+
+	return tw.Flush()
+}
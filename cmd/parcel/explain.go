@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+// runExplain prints why a tracking number does or doesn't match each
+// service.
+func runExplain(e *env, args []string) int {
+	fs, out := flags(e, "explain")
+	verbose := fs.Bool("v", false, "show every check, not just the outcome")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	res, err := parcel.Explain(strings.Join(fs.Args(), " "))
+	if err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	matches := 0
+	rows := make([][]string, 0, len(res))
+	for _, ex := range res {
+		match := "no"
+		if ex.Match {
+			match = "yes"
+			matches++
+		}
+		reason := ex.Reason()
+		if *verbose {
+			reason = strings.Join(ex.Steps, "; ")
+		}
+		rows = append(rows, []string{ex.Courier, ex.ServiceID, ex.Service, match, reason})
+	}
+
+	if err := out.write(res, []string{"COURIER", "ID", "SERVICE", "MATCH", "REASON"}, rows); err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	return exitCode(matches)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+// runLint checks the courier json files in a directory.
+func runLint(e *env, args []string) int {
+	fs, out := flags(e, "lint")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	dir := fs.Arg(0)
+	if info, err := os.Stat(dir); err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	} else if !info.IsDir() {
+		fmt.Fprintf(e.stderr, "parcel: %s is not a directory\n", dir)
+		return exitError
+	}
+
+	issues, err := parcel.Lint(os.DirFS(dir))
+	if err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	rows := make([][]string, 0, len(issues))
+	for _, i := range issues {
+		rows = append(rows, []string{i.File, i.Service, i.Message})
+	}
+	if err := out.write(issues, []string{"FILE", "SERVICE", "PROBLEM"}, rows); err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	if len(issues) > 0 {
+		return exitNoMatch
+	}
+
+	return exitMatch
+}
@@ -0,0 +1,141 @@
+// Command parcel identifies and extracts package tracking numbers.
+//
+// Usage:
+//
+//	parcel track [-json|-csv] <number>
+//	parcel find [-json|-csv] [file...]
+//	parcel explain [-json|-csv] [-v] <number>
+//	parcel batch [-in file] [-out file] [-column name] [-format csv|jsonl] [-workers n]
+//	parcel collisions [-json|-csv] [-n samples] [-seed n] [-matrix]
+//	parcel couriers [-json|-csv] list
+//	parcel couriers [-json] show <id>
+//	parcel lint [-json|-csv] <dir>
+//	parcel serve [-addr host:port] [-max-body bytes] [-max-batch n]
+//
+// Numbers may contain spaces, quoted or not. Find reads standard input if no
+// files are given. Batch classifies a column of a CSV or JSONL file, appending
+// courier, service, tracking_url and valid columns, and writes per courier
+// counts to standard error. Collisions generates valid numbers for every
+// service and reports the other services that also match them. Couriers
+// lists the loaded service definitions or shows one in detail, for debugging
+// numbers that aren't recognized. Lint checks a directory of courier json
+// files and exits 1 if it finds problems. Serve runs the HTTP API from
+// package api.
+//
+// The exit status is 0 if a tracking number matched a single service, 1 if
+// nothing matched, 3 if a number matched more than one service and 2 for
+// usage and other errors. Batch exits 0 unless it fails to read or write.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// Exit codes.
+const (
+	exitMatch     = 0
+	exitNoMatch   = 1
+	exitError     = 2
+	exitAmbiguous = 3
+)
+
+// env holds the command input and outputs so commands can be tested.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a parcel subcommand.
+type command struct {
+	usage string
+	run   func(e *env, args []string) int
+}
+
+// commands is populated in init because the commands refer back to it for
+// their usage.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"track":      {usage: "track [-json|-csv] <number>", run: runTrack},
+		"find":       {usage: "find [-json|-csv] [file...]", run: runFind},
+		"explain":    {usage: "explain [-json|-csv] [-v] <number>", run: runExplain},
+		"batch":      {usage: "batch [-in file] [-out file] [-column name] [-format csv|jsonl] [-workers n]", run: runBatch},
+		"collisions": {usage: "collisions [-json|-csv] [-n samples] [-seed n] [-matrix]", run: runCollisions},
+		"couriers":   {usage: "couriers [-json|-csv] list | couriers [-json] show <id>", run: runCouriers},
+		"lint":       {usage: "lint [-json|-csv] <dir>", run: runLint},
+		"serve":      {usage: "serve [-addr host:port] [-max-body bytes] [-max-batch n]", run: runServe},
+	}
+}
+
+func main() {
+	os.Exit(run(&env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}, os.Args[1:]))
+}
+
+func run(e *env, args []string) int {
+	if len(args) == 0 {
+		usage(e.stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(e.stderr, "parcel: unknown command %q\n", args[0])
+		usage(e.stderr)
+		return exitError
+	}
+
+	return cmd.run(e, args[1:])
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for k := range commands {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage:")
+	for _, k := range names {
+		fmt.Fprintf(w, "  parcel %s\n", commands[k].usage)
+	}
+}
+
+// flagSet returns an empty flag set for a command.
+func flagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("parcel "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: parcel %s\n", commands[name].usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// flags returns a flag set for a command with the output format flags.
+func flags(e *env, name string) (*flag.FlagSet, *output) {
+	fs := flagSet(e, name)
+	out := &output{w: e.stdout}
+	fs.BoolVar(&out.json, "json", false, "write JSON")
+	fs.BoolVar(&out.csv, "csv", false, "write CSV")
+
+	return fs, out
+}
+
+// exitCode returns the exit status for the number of matches of a number.
+func exitCode(matches int) int {
+	switch {
+	case matches == 0:
+		return exitNoMatch
+	case matches > 1:
+		return exitAmbiguous
+	}
+
+	return exitMatch
+}
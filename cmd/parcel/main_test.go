@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		stdin string
+		code  int
+		want  []string
+	}{
+		{
+			name: "track",
+			args: []string{"track", "1Z5R89390357567127"},
+			code: exitMatch,
+			want: []string{"COURIER", "ups", "1Z5R89390357567127"},
+		},
+		{
+			name: "track with spaces",
+			args: []string{"track", "1Z5R", "8939", "0357", "5671", "27"},
+			code: exitMatch,
+			want: []string{"1Z5R89390357567127"},
+		},
+		{
+			name: "track csv",
+			args: []string{"track", "-csv", "1Z5R89390357567127"},
+			code: exitMatch,
+			want: []string{"COURIER,SERVICE,", "ups,UPS,1Z5R89390357567127,"},
+		},
+		{
+			name: "track ambiguous",
+			args: []string{"track", "986578788855"},
+			code: exitAmbiguous,
+			want: []string{"dhl", "fedex"},
+		},
+		{
+			name: "track no match",
+			args: []string{"track", "123"},
+			code: exitNoMatch,
+		},
+		{
+			name:  "find",
+			args:  []string{"find"},
+			stdin: "your parcel RB123456785GB has shipped",
+			code:  exitMatch,
+			want:  []string{"TERM", "RB123456785GB", "s10"},
+		},
+		{
+			name:  "find nothing",
+			args:  []string{"find"},
+			stdin: "nothing to see here",
+			code:  exitNoMatch,
+		},
+		{
+			name: "explain",
+			args: []string{"explain", "1Z5R89390357567128"},
+			code: exitNoMatch,
+			want: []string{`check digit "8" fails mod10, expected "7"`},
+		},
+		{
+			name: "explain verbose",
+			args: []string{"explain", "-v", "1Z5R89390357567127"},
+			code: exitMatch,
+			want: []string{"regex matches", "check digit passes mod10"},
+		},
+		{
+			name: "no command",
+			code: exitError,
+		},
+		{
+			name: "unknown command",
+			args: []string{"ship"},
+			code: exitError,
+		},
+		{
+			name: "missing number",
+			args: []string{"track"},
+			code: exitError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			e := &env{stdin: strings.NewReader(tt.stdin), stdout: &stdout, stderr: &stderr}
+
+			if code := run(e, tt.args); code != tt.code {
+				t.Errorf("run() exit code %d, expected %d\n%s", code, tt.code, stderr.String())
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(stdout.String(), w) {
+					t.Errorf("run() output missing %q\n%s", w, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	e := &env{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}
+
+	if code := run(e, []string{"track", "-json", "1Z5R89390357567127"}); code != exitMatch {
+		t.Fatalf("run() exit code %d, expected %d\n%s", code, exitMatch, stderr.String())
+	}
+
+	var res []map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		t.Fatalf("json.Unmarshal() error %v", err)
+	}
+	if len(res) != 1 || res[0]["courier"] != "ups" {
+		t.Errorf("run() JSON = %v, expected one ups result", res)
+	}
+}
+
+func TestCouriers(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		code int
+		want []string
+	}{
+		{
+			name: "list",
+			args: []string{"couriers", "list"},
+			code: exitMatch,
+			want: []string{"CHECKSUM", "ups", "usps_20", "mod10"},
+		},
+		{
+			name: "list csv",
+			args: []string{"couriers", "-csv", "list"},
+			code: exitMatch,
+			want: []string{"s10,s10,S10,s10,,4,2"},
+		},
+		{
+			name: "show",
+			args: []string{"couriers", "show", "fedex_smartpost"},
+			code: exitMatch,
+			want: []string{
+				"Regex:", "(?P<SerialNumber>",
+				"PCRE:", "(?<SerialNumber>",
+				`Prepend:      "92" if /^(?!92).+/`,
+				"Partner:      usps_91 (carrier)",
+			},
+		},
+		{
+			name: "show lookups",
+			args: []string{"couriers", "show", "s10"},
+			code: exitMatch,
+			want: []string{"Exists:", "Courier", "Great Britain (Royal Mail Group plc)"},
+		},
+		{
+			name: "show json",
+			args: []string{"couriers", "-json", "show", "ups"},
+			code: exitMatch,
+			want: []string{`"pcre":`, `"valid_tests":`},
+		},
+		{
+			name: "show unknown",
+			args: []string{"couriers", "show", "pigeon"},
+			code: exitNoMatch,
+		},
+		{
+			name: "usage",
+			args: []string{"couriers"},
+			code: exitError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			e := &env{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}
+
+			if code := run(e, tt.args); code != tt.code {
+				t.Errorf("run() exit code %d, expected %d\n%s", code, tt.code, stderr.String())
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(stdout.String(), w) {
+					t.Errorf("run() output missing %q\n%s", w, stdout.String())
+				}
+			}
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	good := t.TempDir()
+	bad := t.TempDir()
+	courier := `{"name": "Example", "courier_code": "example", "tracking_numbers": [{
+		"id": "example",
+		"name": "Example",
+		"regex": "(?<SerialNumber>[0-9]{6})(?<CheckDigit>[0-9])",
+		"validation": {"checksum": {"name": "mod7"}},
+		"test_numbers": {"valid": ["1234564"], "invalid": ["1234565"]}
+	}]}`
+	if err := os.WriteFile(filepath.Join(good, "example.json"), []byte(courier), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	broken := strings.Replace(courier, `"1234564"`, `"1234560"`, 1)
+	if err := os.WriteFile(filepath.Join(bad, "example.json"), []byte(broken), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		code int
+		want string
+	}{
+		{"clean", []string{"lint", good}, exitMatch, "FILE"},
+		{"problems", []string{"lint", "-csv", bad}, exitNoMatch, `example.json,example,"valid test number ""1234560"" doesn't match`},
+		{"missing", []string{"lint", filepath.Join(good, "nope")}, exitError, ""},
+		{"not a directory", []string{"lint", filepath.Join(good, "example.json")}, exitError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			e := &env{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}
+
+			if code := run(e, tt.args); code != tt.code {
+				t.Errorf("run() exit code %d, expected %d\n%s", code, tt.code, stderr.String())
+			}
+			if !strings.Contains(stdout.String(), tt.want) {
+				t.Errorf("run() output missing %q\n%s", tt.want, stdout.String())
+			}
+		})
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"pairs", []string{"collisions", "-n", "10"}, []string{"ALSO MATCHED BY", "amazon_logistics", "amazon_international  100%"}},
+		{"matrix", []string{"collisions", "-n", "10", "-matrix"}, []string{"1 amazon_logistics", "SAMPLES"}},
+		{"matrix csv", []string{"collisions", "-n", "10", "-matrix", "-csv"}, []string{"SERVICE,SAMPLES,amazon_logistics,amazon_international,"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			e := &env{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr}
+
+			if code := run(e, tt.args); code != exitMatch {
+				t.Errorf("run() exit code %d, expected %d\n%s", code, exitMatch, stderr.String())
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(stdout.String(), w) {
+					t.Errorf("run() output missing %q\n%s", w, stdout.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"dev.freespoke.com/go-package-tracking/api"
+)
+
+// runServe serves the HTTP API until interrupted.
+func runServe(e *env, args []string) int {
+	fs := flagSet(e, "serve")
+	addr := fs.String("addr", ":8080", "listen address")
+	maxBody := fs.Int64("max-body", api.DefaultMaxBody, "largest request body in bytes")
+	maxBatch := fs.Int("max-batch", api.DefaultMaxBatch, "most numbers in a batch request")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	logger := log.New(e.stderr, "parcel: ", log.LstdFlags)
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: api.NewHandler(api.Config{
+			MaxBody:  *maxBody,
+			MaxBatch: *maxBatch,
+			ErrorLog: logger,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ErrorLog:          logger,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		logger.Printf("listening on %s", *addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	case <-ctx.Done():
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	return exitMatch
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+var trackHeader = []string{"COURIER", "SERVICE", "TRACKING NUMBER", "SERIAL", "CHECK DIGIT", "URL", "DETAILS"}
+
+func trackRow(t parcel.Tracking) []string {
+	return []string{t.Courier, t.Service, t.TrackingNumber, t.SerialNumber, t.CheckDigit, t.TrackingURL, details(t.Details)}
+}
+
+// runTrack prints the services matching a single tracking number.
+func runTrack(e *env, args []string) int {
+	fs, out := flags(e, "track")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	res, err := parcel.Track(strings.Join(fs.Args(), " "))
+	if err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	rows := make([][]string, 0, len(res))
+	for _, t := range res {
+		rows = append(rows, trackRow(t))
+	}
+	if err := out.write(res, trackHeader, rows); err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	return exitCode(len(res))
+}
+
+// runFind lists the tracking numbers found in files or standard input.
+func runFind(e *env, args []string) int {
+	fs, out := flags(e, "find")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	text, err := readInputs(e.stdin, fs.Args())
+	if err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	found, err := parcel.Find(text)
+	if err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	terms := make([]string, 0, len(found))
+	for k := range found {
+		terms = append(terms, k)
+	}
+	sort.Strings(terms)
+
+	code := exitNoMatch
+	rows := make([][]string, 0, len(found))
+	for _, term := range terms {
+		for _, t := range found[term] {
+			rows = append(rows, append([]string{term}, trackRow(t)...))
+		}
+		if c := exitCode(len(found[term])); c == exitAmbiguous || code == exitNoMatch {
+			code = c
+		}
+	}
+
+	if err := out.write(found, append([]string{"TERM"}, trackHeader...), rows); err != nil {
+		fmt.Fprintf(e.stderr, "parcel: %v\n", err)
+		return exitError
+	}
+
+	return code
+}
+
+// readInputs reads and joins the named files, or stdin if there are none.
+// The name "-" also reads stdin.
+func readInputs(stdin io.Reader, names []string) (string, error) {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+
+	var b strings.Builder
+	for _, name := range names {
+		var r io.Reader = stdin
+		if name != "-" {
+			f, err := os.Open(name)
+			if err != nil {
+				return "", err
+			}
+			defer f.Close()
+			r = f
+		}
+		if _, err := io.Copy(&b, r); err != nil {
+			return "", err
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,222 @@
+package parcel
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+
+	"dev.freespoke.com/go-package-tracking/internal"
+)
+
+// sampleAttempts is the most generated candidates per requested sample.
+const sampleAttempts = 20
+
+// CollisionReport counts how often generated valid numbers for one service
+// also match another service.
+type CollisionReport struct {
+	// Services are the service keys, see Tracking.ServiceKey.
+	Services []string `json:"services"`
+
+	// Samples is the number of valid numbers generated for each service.
+	Samples []int `json:"samples"`
+
+	// Matrix[i][j] counts the samples of Services[i] also matched by
+	// Services[j]. The diagonal is zero.
+	Matrix [][]int `json:"matrix"`
+
+	// Examples holds a colliding sample for each non-zero cell, keyed by
+	// "i,j" service keys joined with a space.
+	Examples map[string]string `json:"examples"`
+}
+
+// Collision is a pair of services that matched the same number.
+type Collision struct {
+	Service string `json:"service"` // the service the samples were generated for
+	Other   string `json:"other"`   // the service that also matched
+	Samples int    `json:"samples"`
+	Matches int    `json:"matches"`
+	Example string `json:"example"`
+
+	// Winner is the service Track returns for the pair if a precedence
+	// rule resolves the collision.
+	Winner string `json:"winner,omitempty"`
+}
+
+// ServiceKey identifies the service of a result: its ServiceID, or the
+// courier and service name for the few services without an ID.
+func (t Tracking) ServiceKey() string {
+	return serviceKey(t.Courier, t.ServiceID, t.Service)
+}
+
+func serviceKey(courier, id, name string) string {
+	if id != "" {
+		return id
+	}
+
+	return courier + "/" + name
+}
+
+// Collisions generates up to n valid numbers for every loaded service and
+// runs each through every other service. The same seed gives the same
+// report. Precedence rules are not applied.
+func Collisions(n int, seed int64) CollisionReport {
+	services := internal.Services
+	r := CollisionReport{
+		Services: make([]string, len(services)),
+		Samples:  make([]int, len(services)),
+		Matrix:   make([][]int, len(services)),
+		Examples: map[string]string{},
+	}
+
+	for i, s := range services {
+		r.Services[i] = serviceKey(s.CourierCode, s.ID, s.Name)
+		r.Matrix[i] = make([]int, len(services))
+	}
+
+	for i, s := range services {
+		samples := sample(s, n, rand.New(rand.NewSource(seed+int64(i))))
+		r.Samples[i] = len(samples)
+
+		for _, num := range samples {
+			for j, other := range services {
+				if i == j {
+					continue
+				}
+				if _, ok := evaluate(other, num, nil); ok {
+					r.Matrix[i][j]++
+					key := r.Services[i] + " " + r.Services[j]
+					if _, ok := r.Examples[key]; !ok {
+						r.Examples[key] = num
+					}
+				}
+			}
+		}
+	}
+
+	return r
+}
+
+// Collisions lists the non-zero cells of the matrix, most frequent first.
+func (r CollisionReport) Collisions() []Collision {
+	out := make([]Collision, 0)
+	for i := range r.Matrix {
+		for j, n := range r.Matrix[i] {
+			if n == 0 {
+				continue
+			}
+			out = append(out, Collision{
+				Service: r.Services[i],
+				Other:   r.Services[j],
+				Samples: r.Samples[i],
+				Matches: n,
+				Example: r.Examples[r.Services[i]+" "+r.Services[j]],
+				Winner:  winner(r.Services[i], r.Services[j]),
+			})
+		}
+	}
+
+	sort.SliceStable(out, func(a, b int) bool {
+		ra := float64(out[a].Matches) / float64(out[a].Samples)
+		rb := float64(out[b].Matches) / float64(out[b].Samples)
+		if ra != rb {
+			return ra > rb
+		}
+		return out[a].Service+out[a].Other < out[b].Service+out[b].Other
+	})
+
+	return out
+}
+
+// winner returns the service that wins a collision by precedence, or an
+// empty string if no rule applies.
+func winner(a, b string) string {
+	for _, w := range beaten([]string{a, b}) {
+		return w
+	}
+
+	return ""
+}
+
+// Sample generates up to n distinct valid numbers for the service with the
+// key, see Tracking.ServiceKey, from its regex, lookups and check digit
+// algorithm. It may return fewer if the service rarely produces valid
+// numbers, and none if no loaded service has the key.
+func Sample(key string, n int, rnd *rand.Rand) []string {
+	for _, s := range internal.Services {
+		if serviceKey(s.CourierCode, s.ID, s.Name) == key {
+			return sample(s, n, rnd)
+		}
+	}
+
+	return nil
+}
+
+func sample(service internal.Service, n int, rnd *rand.Rand) []string {
+	groups := map[string][]string{}
+	for _, a := range service.Additional {
+		for _, l := range a.Lookups {
+			if l.Matches != "" {
+				groups[a.RegexGroupName] = append(groups[a.RegexGroupName], l.Matches)
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	out := make([]string, 0, n)
+	for attempt := 0; attempt < n*sampleAttempts && len(out) < n; attempt++ {
+		num, err := service.Regex.Generate(rnd, groups)
+		if err != nil {
+			return out
+		}
+		num = fixCheckDigit(service, normalize(strings.Join(strings.Fields(num), "")))
+
+		if seen[num] {
+			continue
+		}
+		if _, ok := evaluate(service, num, nil); ok {
+			seen[num] = true
+			out = append(out, num)
+		}
+	}
+
+	return out
+}
+
+// fixCheckDigit replaces the check digit of a generated number with the
+// one calculated from its serial number.
+func fixCheckDigit(service internal.Service, num string) string {
+	re := service.Regex.Regex
+	loc := re.FindStringSubmatchIndex(num)
+	if loc == nil {
+		return num
+	}
+
+	var serial string
+	check := -1
+	for i, name := range re.SubexpNames() {
+		if loc[2*i] < 0 {
+			continue
+		}
+		switch name {
+		case "SerialNumber":
+			serial = num[loc[2*i]:loc[2*i+1]]
+		case "CheckDigit":
+			check = i
+		}
+	}
+	if check < 0 {
+		return num
+	}
+
+	prepend := service.Validation.SerialNumberFormat.PrependIf
+	if prepend.Regex.MatchString(serial) {
+		serial = prepend.Content + serial
+	}
+
+	cd, err := service.Validation.Validator.Generate(serial)
+	if err != nil || cd == "" {
+		return num
+	}
+
+	return num[:loc[2*check]] + cd + num[loc[2*check+1]:]
+}
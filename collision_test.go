@@ -0,0 +1,167 @@
+package parcel_test
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/internal"
+)
+
+// Every service must generate numbers that Track matches to it.
+func TestSample(t *testing.T) {
+	for _, service := range internal.Services {
+		key := service.ID
+		if key == "" {
+			key = service.CourierCode + "/" + service.Name
+		}
+		samples := parcel.Sample(key, 10, rand.New(rand.NewSource(1)))
+		if len(samples) == 0 {
+			t.Errorf("parcel.Sample() %s generated no numbers", service.Name)
+			continue
+		}
+
+		for _, num := range samples {
+			res, err := parcel.Track(num)
+			if err != nil {
+				t.Fatalf("parcel.Track() error %v", err)
+			}
+			found := false
+			for _, r := range res {
+				found = found || r.Service == service.Name
+			}
+			if !found {
+				t.Errorf("parcel.Sample() %s generated %q which Track doesn't match", service.Name, num)
+			}
+		}
+	}
+}
+
+func TestSampleUnknown(t *testing.T) {
+	if got := parcel.Sample("nothing", 10, rand.New(rand.NewSource(1))); len(got) != 0 {
+		t.Errorf("parcel.Sample() unknown service = %v, expected none", got)
+	}
+}
+
+func TestCollisions(t *testing.T) {
+	a := parcel.Collisions(20, 7)
+	b := parcel.Collisions(20, 7)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("parcel.Collisions() differs for the same seed")
+	}
+
+	for i := range a.Matrix {
+		if a.Matrix[i][i] != 0 {
+			t.Errorf("parcel.Collisions() diagonal %s = %d", a.Services[i], a.Matrix[i][i])
+		}
+	}
+
+	found := false
+	for _, c := range a.Collisions() {
+		if c.Service == "amazon_logistics" && c.Other == "amazon_international" {
+			found = true
+			if c.Matches != c.Samples || c.Example == "" {
+				t.Errorf("parcel.Collisions() amazon collision = %+v", c)
+			}
+		}
+	}
+	if !found {
+		t.Error("parcel.Collisions() missing amazon_logistics/amazon_international")
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	defer parcel.ResetPrecedence()
+
+	services := func() []string {
+		res, err := parcel.Track("986578788855")
+		if err != nil {
+			t.Fatalf("parcel.Track() error %v", err)
+		}
+		out := make([]string, 0, len(res))
+		for _, r := range res {
+			out = append(out, r.ServiceKey())
+		}
+		return out
+	}
+
+	if got := services(); !reflect.DeepEqual(got, []string{"dhl_express", "fedex_12"}) {
+		t.Fatalf("parcel.Track() = %v, expected both services", got)
+	}
+
+	if err := parcel.RegisterPrecedence(parcel.Precedence{Winner: "fedex_12", Loser: "dhl_express"}); err != nil {
+		t.Fatalf("parcel.RegisterPrecedence() error %v", err)
+	}
+	if got := services(); !reflect.DeepEqual(got, []string{"fedex_12"}) {
+		t.Errorf("parcel.Track() = %v, expected fedex_12", got)
+	}
+
+	ex, err := parcel.Explain("986578788855")
+	if err != nil {
+		t.Fatalf("parcel.Explain() error %v", err)
+	}
+	for _, e := range ex {
+		if e.ServiceID == "dhl_express" && (e.Match || e.Reason() != "fedex_12 takes precedence") {
+			t.Errorf("parcel.Explain() dhl_express = %+v", e)
+		}
+	}
+
+	// The reversed rule replaces the first one.
+	if err := parcel.RegisterPrecedence(parcel.Precedence{Winner: "dhl_express", Loser: "fedex_12"}); err != nil {
+		t.Fatalf("parcel.RegisterPrecedence() error %v", err)
+	}
+	if got := services(); !reflect.DeepEqual(got, []string{"dhl_express"}) {
+		t.Errorf("parcel.Track() = %v, expected dhl_express", got)
+	}
+
+	for _, c := range parcel.Collisions(20, 1).Collisions() {
+		if c.Service == "fedex_12" && c.Other == "dhl_express" && c.Winner != "dhl_express" {
+			t.Errorf("parcel.Collisions() winner %q, expected dhl_express", c.Winner)
+		}
+	}
+}
+
+func TestPrecedenceCycle(t *testing.T) {
+	defer parcel.ResetPrecedence()
+
+	err := parcel.RegisterPrecedence(
+		parcel.Precedence{Winner: "fedex_12", Loser: "dhl_express"},
+		parcel.Precedence{Winner: "dhl_express", Loser: "ups"},
+	)
+	if err != nil {
+		t.Fatalf("parcel.RegisterPrecedence() error %v", err)
+	}
+
+	// The third rule would let every service beat itself.
+	err = parcel.RegisterPrecedence(parcel.Precedence{Winner: "ups", Loser: "fedex_12"})
+	if !errors.Is(err, parcel.ErrPrecedenceCycle) {
+		t.Errorf("parcel.RegisterPrecedence() error = %v, expected %v", err, parcel.ErrPrecedenceCycle)
+	}
+	err = parcel.RegisterPrecedence(parcel.Precedence{Winner: "usps_20", Loser: "usps_20"})
+	if !errors.Is(err, parcel.ErrPrecedenceCycle) {
+		t.Errorf("parcel.RegisterPrecedence() self rule error = %v, expected %v", err, parcel.ErrPrecedenceCycle)
+	}
+
+	res, err := parcel.Track("986578788855")
+	if err != nil {
+		t.Fatalf("parcel.Track() error %v", err)
+	}
+	if len(res) != 1 || res[0].ServiceKey() != "fedex_12" {
+		t.Errorf("parcel.Track() = %v, expected fedex_12", res)
+	}
+
+	// Even a cycle among the matched services keeps a result.
+	parcel.ForcePrecedence(
+		parcel.Precedence{Winner: "fedex_12", Loser: "dhl_express"},
+		parcel.Precedence{Winner: "dhl_express", Loser: "fedex_12"},
+	)
+	res, err = parcel.Track("986578788855")
+	if err != nil {
+		t.Fatalf("parcel.Track() error %v", err)
+	}
+	if len(res) != 1 {
+		t.Errorf("parcel.Track() with a cycle = %v, expected one result", res)
+	}
+}
@@ -0,0 +1,212 @@
+package parcel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"dev.freespoke.com/go-package-tracking/internal"
+)
+
+// ContextMode is how Find uses courier keywords near a number.
+type ContextMode int
+
+const (
+	// ContextBoost orders results with keyword evidence first.
+	ContextBoost ContextMode = iota
+
+	// ContextSelect drops results without keyword evidence if any result
+	// of the number has some.
+	ContextSelect
+)
+
+var (
+	aliasMu sync.RWMutex
+
+	// courierAliases are keywords for a courier besides its name and code.
+	courierAliases = map[string][]string{
+		"amazon":      {"amzl", "amazon logistics"},
+		"canada_post": {"canadapost", "postes canada", "postes"},
+		"fedex":       {"fed ex", "federal express"},
+		"lasership":   {"laser ship"},
+		"ontrac":      {"on trac"},
+		"ups":         {"united parcel service"},
+		"usps":        {"postal service", "us postal", "u.s. postal", "us mail"},
+	}
+)
+
+// WithContext makes Find look for courier names, codes and aliases up to
+// window words before and after each number. Matching results get Evidence
+// and are boosted or selected depending on the mode. Track ignores it.
+func WithContext(window int, mode ContextMode) Option {
+	return func(f *filter) {
+		f.context = window
+		f.contextMode = mode
+	}
+}
+
+// RegisterAlias adds keywords for a courier code, such as a brand name
+// used by a local partner.
+func RegisterAlias(courier string, aliases ...string) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+
+	courierAliases[courier] = append(courierAliases[courier], aliases...)
+}
+
+// keywordsFor returns the keywords of a courier split into normalized words.
+func keywordsFor(courier string) [][]string {
+	aliasMu.RLock()
+	phrases := append([]string{courier, strings.ReplaceAll(courier, "_", " ")}, courierAliases[courier]...)
+	aliasMu.RUnlock()
+
+	for _, s := range internal.Services {
+		if s.CourierCode == courier {
+			phrases = append(phrases, s.CourierName)
+			break
+		}
+	}
+
+	seen := map[string]bool{}
+	out := make([][]string, 0, len(phrases))
+	for _, p := range phrases {
+		words := contextWords(strings.Fields(p))
+		key := strings.Join(words, " ")
+		if len(words) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, words)
+	}
+
+	return out
+}
+
+// contextWords normalizes words for keyword matching.
+func contextWords(words []string) []string {
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimFunc(w, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}))
+		if w != "" {
+			out = append(out, w)
+		}
+	}
+
+	return out
+}
+
+// disambiguate adds keyword evidence to the results of each term found and
+// boosts or selects the results with evidence.
+func disambiguate(terms []string, out map[string][]Tracking, window int, mode ContextMode) {
+	if window <= 0 {
+		return
+	}
+
+	keywords := map[string][][]string{}
+	for i, term := range terms {
+		res, ok := out[term]
+		if !ok {
+			continue
+		}
+
+		before := contextWords(terms[maxInt(0, i-window):i])
+		after := contextWords(terms[i+1 : minInt(len(terms), i+1+window)])
+
+		for k := range res {
+			c := res[k].Courier
+			if _, ok := keywords[c]; !ok {
+				keywords[c] = keywordsFor(c)
+			}
+			for _, kw := range keywords[c] {
+				if d := lastIndex(before, kw); d >= 0 {
+					res[k].addEvidence(evidence(kw, len(before)-d-len(kw)+1, "before"))
+				}
+				if d := index(after, kw); d >= 0 {
+					res[k].addEvidence(evidence(kw, d+1, "after"))
+				}
+			}
+		}
+	}
+
+	for term, res := range out {
+		sort.SliceStable(res, func(a, b int) bool {
+			return len(res[a].Evidence) > 0 && len(res[b].Evidence) == 0
+		})
+		if mode == ContextSelect && len(res[0].Evidence) > 0 {
+			n := 0
+			for n < len(res) && len(res[n].Evidence) > 0 {
+				n++
+			}
+			out[term] = res[:n]
+		}
+	}
+}
+
+// evidence describes a keyword found n words from a number.
+func evidence(keyword []string, n int, where string) string {
+	words := "words"
+	if n == 1 {
+		words = "word"
+	}
+
+	return fmt.Sprintf("%q %d %s %s", strings.Join(keyword, " "), n, words, where)
+}
+
+func (t *Tracking) addEvidence(e string) {
+	for _, v := range t.Evidence {
+		if v == e {
+			return
+		}
+	}
+	t.Evidence = append(t.Evidence, e)
+}
+
+// index returns the first position of the phrase in words, or -1.
+func index(words, phrase []string) int {
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		if equalWords(words[i:i+len(phrase)], phrase) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// lastIndex returns the last position of the phrase in words, or -1.
+func lastIndex(words, phrase []string) int {
+	for i := len(words) - len(phrase); i >= 0; i-- {
+		if equalWords(words[i:i+len(phrase)], phrase) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,62 @@
+package parcel_test
+
+import (
+	"reflect"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+func TestFindContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		opts     []parcel.Option
+		want     []string
+		evidence []string
+	}{
+		{"no context", "FedEx tracking: 986578788855", nil, []string{"dhl_express", "fedex_12"}, nil},
+		{"boost", "FedEx tracking: 986578788855", []parcel.Option{parcel.WithContext(3, parcel.ContextBoost)}, []string{"fedex_12", "dhl_express"}, []string{`"fedex" 2 words before`}},
+		{"select", "FedEx tracking: 986578788855", []parcel.Option{parcel.WithContext(3, parcel.ContextSelect)}, []string{"fedex_12"}, []string{`"fedex" 2 words before`}},
+		{"after", "986578788855 (shipped with DHL)", []parcel.Option{parcel.WithContext(3, parcel.ContextSelect)}, []string{"dhl_express"}, []string{`"dhl" 3 words after`}},
+		{"alias", "Federal Express: 986578788855", []parcel.Option{parcel.WithContext(3, parcel.ContextSelect)}, []string{"fedex_12"}, []string{`"federal express" 1 word before`}},
+		{"outside window", "FedEx sent it today, number 986578788855", []parcel.Option{parcel.WithContext(2, parcel.ContextSelect)}, []string{"dhl_express", "fedex_12"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := parcel.Find(tt.in, tt.opts...)
+			if err != nil {
+				t.Fatalf("parcel.Find() error %v", err)
+			}
+			got := make([]string, 0)
+			for _, r := range res["986578788855"] {
+				got = append(got, r.ServiceKey())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parcel.Find() = %v, expected %v", got, tt.want)
+			}
+			if ev := res["986578788855"][0].Evidence; !reflect.DeepEqual(ev, tt.evidence) {
+				t.Errorf("parcel.Find() evidence = %q, expected %q", ev, tt.evidence)
+			}
+		})
+	}
+}
+
+func TestRegisterAlias(t *testing.T) {
+	t.Cleanup(parcel.SaveAliases())
+	parcel.RegisterAlias("dhl", "dhl parcel uk")
+
+	res, err := parcel.Find("via DHL Parcel UK: 986578788855", parcel.WithContext(5, parcel.ContextSelect))
+	if err != nil {
+		t.Fatalf("parcel.Find() error %v", err)
+	}
+	got := res["986578788855"]
+	if len(got) != 1 || got[0].Courier != "dhl" {
+		t.Fatalf("parcel.Find() = %+v, expected dhl", got)
+	}
+	want := []string{`"dhl" 3 words before`, `"dhl parcel uk" 1 word before`}
+	if !reflect.DeepEqual(got[0].Evidence, want) {
+		t.Errorf("parcel.Find() evidence = %q, expected %q", got[0].Evidence, want)
+	}
+}
@@ -0,0 +1,329 @@
+// Package email extracts tracking numbers from shipping confirmation emails.
+//
+// Messages are parsed as RFC 5322 with MIME parts: multipart bodies,
+// quoted-printable and base64 encodings, character sets, HTML parts and
+// forwarded messages attached as message/rfc822. Numbers are searched in the
+// subject, the text of every part and the links of HTML parts.
+package email
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"golang.org/x/net/html/charset"
+)
+
+var ErrDepth = errors.New("email nested too deeply")
+
+// maxDepth limits nested multiparts and forwarded messages.
+const maxDepth = 16
+
+// Origin is the kind of content a number was found in.
+type Origin string
+
+const (
+	OriginSubject Origin = "subject"
+	OriginText    Origin = "text"
+	OriginLink    Origin = "link"
+)
+
+// Message is a parsed email.
+type Message struct {
+	Header  mail.Header
+	Subject string // decoded
+	Parts   []Part
+}
+
+// Part is a text part of a message, or the subject of a forwarded message.
+type Part struct {
+	// Path is the MIME section number, such as "1.2". Parts of a forwarded
+	// message continue the path of the message/rfc822 part.
+	Path        string
+	ContentType string
+	Filename    string
+
+	// Text is the decoded text. HTML is converted to text, with the link
+	// targets kept in Links and the markup in HTML.
+	Text  string
+	Links []string
+	HTML  string
+}
+
+// Source is where a number was found.
+type Source struct {
+	Origin      Origin
+	Path        string // MIME section number, empty for the subject
+	ContentType string
+	Link        string // the link, for OriginLink
+
+	// Element is the path of the HTML element holding the number, and
+	// Attr its attribute if not found in the element text. See
+	// parcel.HTMLMatch.
+	Element string
+	Attr    string
+}
+
+// Found is a tracking number found in a message.
+type Found struct {
+	Number   string
+	Tracking []parcel.Tracking
+	Sources  []Source
+}
+
+// Parse reads a message and decodes its text parts. Parts with other media
+// types, such as images, are skipped.
+func Parse(r io.Reader) (*Message, error) {
+	msg, err := mail.ReadMessage(bufio.NewReader(r))
+	if err != nil {
+		return nil, fmt.Errorf("email: %w", err)
+	}
+
+	m := &Message{Header: msg.Header, Subject: decodeHeader(msg.Header.Get("Subject"))}
+	if err := m.parsePart(textproto.MIMEHeader(msg.Header), msg.Body, "", 0); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Extract parses a message and finds its tracking numbers. See
+// Message.Find.
+func Extract(r io.Reader, opts ...parcel.Option) ([]Found, error) {
+	m, err := Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Find(opts...)
+}
+
+// parsePart adds the text parts of a MIME entity at path.
+func (m *Message) parsePart(header textproto.MIMEHeader, body io.Reader, path string, depth int) error {
+	if depth > maxDepth {
+		return ErrDepth
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/plain", map[string]string{}
+	}
+	body = decodeTransfer(header.Get("Content-Transfer-Encoding"), body)
+
+	switch {
+	case strings.HasPrefix(mediaType, "multipart/"):
+		mr := multipart.NewReader(body, params["boundary"])
+		for i := 1; ; i++ {
+			p, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("email: part %s: %w", join(path, i), err)
+			}
+			if err := m.parsePart(p.Header, p, join(path, i), depth+1); err != nil {
+				return err
+			}
+		}
+
+	case mediaType == "message/rfc822":
+		msg, err := mail.ReadMessage(bufio.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("email: part %s: %w", path, err)
+		}
+		m.Parts = append(m.Parts, Part{
+			Path:        path,
+			ContentType: mediaType,
+			Text:        decodeHeader(msg.Header.Get("Subject")),
+		})
+		return m.parsePart(textproto.MIMEHeader(msg.Header), msg.Body, join(path, 1), depth+1)
+
+	case strings.HasPrefix(mediaType, "text/"):
+		if path == "" {
+			path = "1"
+		}
+		r, err := charset.NewReaderLabel(params["charset"], body)
+		if err != nil {
+			r = body
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("email: part %s: %w", path, err)
+		}
+
+		p := Part{Path: path, ContentType: mediaType, Text: string(b)}
+		if _, dp, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+			p.Filename = decodeHeader(dp["filename"])
+		}
+		if mediaType == "text/html" {
+			p.HTML = p.Text
+			p.Text, p.Links = htmlText(p.HTML)
+		}
+		m.Parts = append(m.Parts, p)
+	}
+
+	return nil
+}
+
+// Find searches the subject, parts and links for tracking numbers. Each
+// number is listed once, in the order first found, with every place it was
+// found in. HTML parts are searched with parcel.FindHTML. Options are passed
+// to parcel.Find.
+func (m *Message) Find(opts ...parcel.Option) ([]Found, error) {
+	var out []Found
+	index := make(map[string]int)
+
+	add := func(num string, res []parcel.Tracking, src Source) {
+		i, ok := index[num]
+		if !ok {
+			i = len(out)
+			index[num] = i
+			out = append(out, Found{Number: num, Tracking: res})
+		}
+		if !hasSource(out[i].Sources, src) {
+			out[i].Sources = append(out[i].Sources, src)
+		}
+	}
+	addText := func(text string, src Source) error {
+		res, err := parcel.Find(text, opts...)
+		if err != nil {
+			return err
+		}
+		for _, term := range sortedTerms(text, res) {
+			add(parcel.TrimTerm(term), res[term], src)
+		}
+		return nil
+	}
+
+	if err := addText(m.Subject, Source{Origin: OriginSubject}); err != nil {
+		return nil, err
+	}
+	for _, p := range m.Parts {
+		src := Source{Origin: OriginText, Path: p.Path, ContentType: p.ContentType}
+		switch {
+		case p.ContentType == "message/rfc822":
+			src.Origin = OriginSubject
+		case p.HTML != "":
+			found, err := parcel.FindHTML(p.HTML, opts...)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range found {
+				src := src
+				src.Element, src.Attr = f.Path, f.Attr
+				if f.Attr == "href" {
+					src.Origin, src.Link = OriginLink, linkWith(p.Links, f.Number)
+				}
+				add(f.Number, f.Tracking, src)
+			}
+			continue
+		}
+		if err := addText(p.Text, src); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// linkWith returns the first link containing a number.
+func linkWith(links []string, num string) string {
+	for _, l := range links {
+		if strings.Contains(l, num) {
+			return l
+		}
+	}
+
+	return ""
+}
+
+// sortedTerms returns the Find results in the order they appear in text.
+func sortedTerms(text string, res map[string][]parcel.Tracking) []string {
+	terms := make([]string, 0, len(res))
+	for k := range res {
+		terms = append(terms, k)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		a, b := strings.Index(text, terms[i]), strings.Index(text, terms[j])
+		if a != b {
+			return a < b
+		}
+		return terms[i] < terms[j]
+	})
+
+	return terms
+}
+
+func hasSource(sources []Source, s Source) bool {
+	for _, v := range sources {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeTransfer undoes a Content-Transfer-Encoding.
+func decodeTransfer(enc string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(enc)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &base64Cleaner{r: r})
+	}
+
+	return r
+}
+
+// base64Cleaner drops the line breaks and stray characters of a base64 body.
+type base64Cleaner struct {
+	r io.Reader
+}
+
+func (c *base64Cleaner) Read(p []byte) (int, error) {
+	for {
+		n, err := c.r.Read(p)
+		j := 0
+		for _, b := range p[:n] {
+			if b >= 'A' && b <= 'Z' || b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b == '+' || b == '/' || b == '=' {
+				p[j] = b
+				j++
+			}
+		}
+		if j > 0 || err != nil {
+			return j, err
+		}
+	}
+}
+
+var headerDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+
+// decodeHeader decodes RFC 2047 encoded words, keeping the raw value if
+// that fails.
+func decodeHeader(s string) string {
+	if d, err := headerDecoder.DecodeHeader(s); err == nil {
+		return d
+	}
+
+	return s
+}
+
+// join appends a section number to a MIME path.
+func join(path string, i int) string {
+	if path == "" {
+		return fmt.Sprint(i)
+	}
+
+	return fmt.Sprintf("%s.%d", path, i)
+}
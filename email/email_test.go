@@ -0,0 +1,158 @@
+package email_test
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"dev.freespoke.com/go-package-tracking/email"
+)
+
+var htmlBody = base64.StdEncoding.EncodeToString([]byte(`<html><head><title>7035114477138472</title><style>p{}</style></head>
+<body><p>Your order shipped!</p>
+<p><a href="https://tools.usps.com/go/TrackConfirmAction?tLabels=9400111201080805483016">Track your package</a></p>
+<table><tr><td>Tracking</td><td>1Z5R89390357567127</td></tr></table>
+<p>FedEx <b>9865</b>78788855</p>
+<a href="mailto:help@example.com">help</a></body></html>`))
+
+var confirmation = "From: Shop <orders@shop.example>\r\n" +
+	"To: me@example.com\r\n" +
+	"Subject: =?UTF-8?Q?Your_order_has_shipped_=E2=80=93_RB123456785GB?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"b1\"\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/plain; charset=ISO-8859-1\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Gr=FC=DFe! UPS tracking: 1Z5R89390357567127, ships to your door. Long =\r\n" +
+	"line continues here.\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	wrap(htmlBody, 76) +
+	"--b1\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"iVBORw0KGgo=\r\n" +
+	"--b1--\r\n"
+
+func wrap(s string, n int) string {
+	var b strings.Builder
+	for len(s) > n {
+		b.WriteString(s[:n] + "\r\n")
+		s = s[n:]
+	}
+	b.WriteString(s + "\r\n")
+
+	return b.String()
+}
+
+func TestParse(t *testing.T) {
+	m, err := email.Parse(strings.NewReader(confirmation))
+	if err != nil {
+		t.Fatalf("email.Parse() error %v", err)
+	}
+
+	if m.Subject != "Your order has shipped – RB123456785GB" {
+		t.Errorf("email.Parse() subject = %q", m.Subject)
+	}
+	if len(m.Parts) != 2 {
+		t.Fatalf("email.Parse() parts = %+v, expected 2", m.Parts)
+	}
+
+	text := m.Parts[0]
+	if text.Path != "1" || text.ContentType != "text/plain" || !strings.HasPrefix(text.Text, "Grüße! UPS") || !strings.Contains(text.Text, "Long line continues") {
+		t.Errorf("email.Parse() text part = %+v", text)
+	}
+
+	h := m.Parts[1]
+	if h.Path != "2" || h.ContentType != "text/html" {
+		t.Errorf("email.Parse() html part = %+v", h)
+	}
+	if strings.Contains(h.Text, "<") || strings.Contains(h.Text, "7035114477138472") || !strings.Contains(h.Text, "Tracking\n") {
+		t.Errorf("email.Parse() html text = %q", h.Text)
+	}
+	if want := []string{"https://tools.usps.com/go/TrackConfirmAction?tLabels=9400111201080805483016"}; !reflect.DeepEqual(h.Links, want) {
+		t.Errorf("email.Parse() links = %q, expected %q", h.Links, want)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	found, err := email.Extract(strings.NewReader(confirmation))
+	if err != nil {
+		t.Fatalf("email.Extract() error %v", err)
+	}
+
+	want := map[string][]email.Source{
+		"RB123456785GB": {{Origin: email.OriginSubject}},
+		"1Z5R89390357567127": {
+			{Origin: email.OriginText, Path: "1", ContentType: "text/plain"},
+			{Origin: email.OriginText, Path: "2", ContentType: "text/html", Element: "/html/body/table/tr/td[2]"},
+		},
+		"986578788855": {{Origin: email.OriginText, Path: "2", ContentType: "text/html", Element: "/html/body/p[3]"}},
+		"9400111201080805483016": {{
+			Origin:      email.OriginLink,
+			Path:        "2",
+			ContentType: "text/html",
+			Link:        "https://tools.usps.com/go/TrackConfirmAction?tLabels=9400111201080805483016",
+			Element:     "/html/body/p[2]/a",
+			Attr:        "href",
+		}},
+	}
+
+	got := make(map[string][]email.Source)
+	var order []string
+	for _, f := range found {
+		if len(f.Tracking) == 0 {
+			t.Errorf("email.Extract() %s has no tracking results", f.Number)
+		}
+		got[f.Number] = f.Sources
+		order = append(order, f.Number)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("email.Extract() = %+v, expected %+v", got, want)
+	}
+	if want := []string{"RB123456785GB", "1Z5R89390357567127", "9400111201080805483016", "986578788855"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("email.Extract() order = %v, expected %v", order, want)
+	}
+}
+
+func TestForwarded(t *testing.T) {
+	msg := "Subject: Fwd: shipped\r\n" +
+		"Content-Type: multipart/mixed; boundary=outer\r\n" +
+		"\r\n" +
+		"--outer\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"See below.\r\n" +
+		"--outer\r\n" +
+		"Content-Type: message/rfc822\r\n" +
+		"\r\n" +
+		"Subject: Shipped via FedEx 986578788855\r\n" +
+		"Content-Type: text/plain\r\n" +
+		"\r\n" +
+		"Thanks for your order.\r\n" +
+		"--outer--\r\n"
+
+	found, err := email.Extract(strings.NewReader(msg))
+	if err != nil {
+		t.Fatalf("email.Extract() error %v", err)
+	}
+	if len(found) != 1 || found[0].Number != "986578788855" {
+		t.Fatalf("email.Extract() = %+v, expected 986578788855", found)
+	}
+	want := []email.Source{{Origin: email.OriginSubject, Path: "2", ContentType: "message/rfc822"}}
+	if !reflect.DeepEqual(found[0].Sources, want) {
+		t.Errorf("email.Extract() sources = %+v, expected %+v", found[0].Sources, want)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := email.Parse(strings.NewReader("not a message")); err == nil {
+		t.Error("email.Parse() expected an error")
+	}
+}
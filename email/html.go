@@ -0,0 +1,92 @@
+package email
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blocks are the elements that break text lines.
+var blocks = map[string]bool{
+	"address": true, "article": true, "blockquote": true, "br": true,
+	"dd": true, "div": true, "dl": true, "dt": true, "footer": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"header": true, "hr": true, "li": true, "ol": true, "p": true,
+	"pre": true, "section": true, "table": true, "td": true, "th": true,
+	"tr": true, "ul": true,
+}
+
+// htmlText converts an HTML document to text and returns the web links of
+// its anchors in document order.
+func htmlText(doc string) (string, []string) {
+	var (
+		b     strings.Builder
+		links []string
+		seen  = make(map[string]bool)
+		skip  int
+	)
+
+	z := html.NewTokenizer(strings.NewReader(doc))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return tidy(b.String()), links
+
+		case html.TextToken:
+			if skip == 0 {
+				// Line breaks in HTML text are spaces.
+				b.WriteString(strings.NewReplacer("\r", " ", "\n", " ", "\t", " ").Replace(string(z.Text())))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			switch tok.Data {
+			case "script", "style", "head":
+				if tok.Type == html.StartTagToken {
+					skip++
+				}
+			case "a", "area":
+				for _, a := range tok.Attr {
+					href := strings.TrimSpace(a.Val)
+					if a.Key == "href" && isWebLink(href) && !seen[href] {
+						seen[href] = true
+						links = append(links, href)
+					}
+				}
+			}
+			if blocks[tok.Data] {
+				b.WriteByte('\n')
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "script", "style", "head":
+				if skip > 0 {
+					skip--
+				}
+			}
+			if blocks[string(name)] {
+				b.WriteByte('\n')
+			}
+		}
+	}
+}
+
+// tidy trims the lines of the text and drops empty ones.
+func tidy(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, l := range lines {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			out = append(out, l)
+		}
+	}
+
+	return strings.Join(out, "\n")
+}
+
+func isWebLink(s string) bool {
+	s = strings.ToLower(s)
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
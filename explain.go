@@ -0,0 +1,95 @@
+package parcel
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Explanation describes how a tracking number was evaluated against a
+// single service.
+type Explanation struct {
+	Courier   string
+	Service   string
+	ServiceID string
+
+	// Extracted values, if the regex matched.
+	SerialNumber string
+	CheckDigit   string
+
+	Match bool
+
+	// Steps lists each check in order. The last step of a failed match is
+	// the reason it failed.
+	Steps []string
+}
+
+// Explain evaluates a tracking number against every service and reports why
+// each one did or didn't match. Matching services are the ones returned by
+// Track. Options restrict the services evaluated.
+func Explain(in string, opts ...Option) ([]Explanation, error) {
+	return defaultRegistry.Explain(in, opts...)
+}
+
+// Explain evaluates a tracking number against the registry services. See
+// Explain.
+func (r *Registry) Explain(in string, opts ...Option) ([]Explanation, error) {
+	if len(in) != utf8.RuneCountInString(in) {
+		return nil, ErrBadString
+	}
+	if len(r.services) == 0 {
+		return nil, ErrNoServices
+	}
+
+	f := newFilter(opts)
+	in = normalize(in)
+	services := r.servicesFor(f)
+	out := make([]Explanation, 0, len(services))
+	for _, service := range services {
+		ex := &Explanation{
+			Courier:   service.CourierCode,
+			Service:   service.Name,
+			ServiceID: service.ID,
+		}
+		var t Tracking
+		t, ex.Match = evaluate(service, in, ex)
+		if ex.Match && !r.allowsResult(f, t) {
+			ex.Match = false
+			ex.step("country %s is filtered out", t.Details["CountryCode"])
+		}
+		out = append(out, *ex)
+	}
+
+	// Match the results of Track after precedence rules.
+	var keys []string
+	for _, ex := range out {
+		if ex.Match {
+			keys = append(keys, serviceKey(ex.Courier, ex.ServiceID, ex.Service))
+		}
+	}
+	lost := beaten(keys)
+	for i, ex := range out {
+		if winner, ok := lost[serviceKey(ex.Courier, ex.ServiceID, ex.Service)]; ok && ex.Match {
+			out[i].Match = false
+			out[i].step("%s takes precedence", winner)
+		}
+	}
+
+	return out, nil
+}
+
+// Reason returns the last step of the evaluation.
+func (ex Explanation) Reason() string {
+	if len(ex.Steps) == 0 {
+		return ""
+	}
+
+	return ex.Steps[len(ex.Steps)-1]
+}
+
+// step records a check. It does nothing on a nil explanation.
+func (ex *Explanation) step(format string, args ...any) {
+	if ex == nil {
+		return
+	}
+	ex.Steps = append(ex.Steps, fmt.Sprintf(format, args...))
+}
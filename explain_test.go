@@ -0,0 +1,51 @@
+package parcel_test
+
+import (
+	"strings"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		in      string
+		service string
+		match   bool
+		reason  string
+	}{
+		{in: "1Z5R89390357567127", service: "ups", match: true, reason: "check digit passes mod10"},
+		{in: "1Z5R89390357567128", service: "ups", match: false, reason: `check digit "8" fails mod10, expected "7"`},
+		{in: "RB123456785XX", service: "s10", match: false, reason: "no Courier lookup for the extracted value"},
+		{in: "986578788855", service: "usps_20", match: false, reason: "regex does not match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parcel.Explain(tt.in)
+			if err != nil {
+				t.Fatalf("parcel.Explain() error %v", err)
+			}
+			track, _ := parcel.Track(tt.in)
+
+			matches := 0
+			for _, ex := range got {
+				if ex.Match {
+					matches++
+				}
+				if ex.ServiceID != tt.service {
+					continue
+				}
+				if ex.Match != tt.match {
+					t.Errorf("Match = %v, want %v: %v", ex.Match, tt.match, ex.Steps)
+				}
+				if !strings.HasPrefix(ex.Reason(), tt.reason) {
+					t.Errorf("Reason() = %q, want %q", ex.Reason(), tt.reason)
+				}
+			}
+			if matches != len(track) {
+				t.Errorf("parcel.Explain() matched %d services, parcel.Track() matched %d", matches, len(track))
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package parcel
+
+// ResetPrecedence removes every precedence rule.
+func ResetPrecedence() {
+	precMu.Lock()
+	defer precMu.Unlock()
+
+	precedence = nil
+}
+
+// SaveURLTemplates returns a function restoring the registered URL
+// templates to their current state.
+func SaveURLTemplates() func() {
+	urlMu.Lock()
+	defer urlMu.Unlock()
+
+	saved := append([]URLTemplate(nil), urlTemplates...)
+	return func() {
+		urlMu.Lock()
+		defer urlMu.Unlock()
+
+		urlTemplates = saved
+	}
+}
+
+// SaveAliases returns a function restoring the registered courier aliases
+// to their current state.
+func SaveAliases() func() {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+
+	saved := make(map[string][]string, len(courierAliases))
+	for k, v := range courierAliases {
+		saved[k] = append([]string(nil), v...)
+	}
+	return func() {
+		aliasMu.Lock()
+		defer aliasMu.Unlock()
+
+		courierAliases = saved
+	}
+}
+
+// ForcePrecedence replaces the precedence rules without checking them for
+// cycles.
+func ForcePrecedence(rules ...Precedence) {
+	precMu.Lock()
+	defer precMu.Unlock()
+
+	precedence = rules
+}
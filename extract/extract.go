@@ -0,0 +1,193 @@
+// Package extract finds tracking numbers in documents such as PDF invoices
+// and scanned labels.
+//
+// Documents are read through a TextSource yielding the text of each page.
+// The pdf package reads the text layer of PDF files, and NewOCRSource
+// plugs in the output of any OCR engine, keeping its bounding boxes.
+package extract
+
+import (
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+// TextSource yields the text of a document page by page.
+type TextSource interface {
+	// Next returns the next page, or io.EOF after the last one.
+	Next() (Page, error)
+}
+
+// Page is the text of a single page.
+type Page struct {
+	Number int // starting at 1
+	Text   string
+
+	// Boxes are the positioned words of the text, if the source knows them.
+	Boxes []Box
+}
+
+// Box is a word on a page.
+type Box struct {
+	Text string
+
+	// The bounding box, in the source units, such as pixels from the top
+	// left corner of a scanned image.
+	X, Y, Width, Height float64
+
+	// Start and End are the byte offsets of Text in Page.Text.
+	Start, End int
+}
+
+// Match is a tracking number found on a page.
+type Match struct {
+	Number   string
+	Tracking []parcel.Tracking
+	Page     int
+
+	// Start and End are the byte offsets of the number in Page.Text,
+	// including any white space splitting it.
+	Start, End int
+
+	// Boxes are the page boxes overlapping the number.
+	Boxes []Box
+}
+
+// Find reads every page of the source and finds their tracking numbers,
+// ordered by page and position. Options are passed to parcel.Find.
+func Find(src TextSource, opts ...parcel.Option) ([]Match, error) {
+	var out []Match
+	for {
+		page, err := src.Next()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := FindPage(page, opts...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m...)
+	}
+}
+
+// FindPage finds the tracking numbers of a single page.
+func FindPage(page Page, opts ...parcel.Option) ([]Match, error) {
+	res, err := parcel.Find(page.Text, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Match
+	for term, t := range res {
+		num := parcel.TrimTerm(term)
+		for _, loc := range locate(page.Text, num) {
+			out = append(out, Match{
+				Number:   num,
+				Tracking: t,
+				Page:     page.Number,
+				Start:    loc[0],
+				End:      loc[1],
+				Boxes:    overlapping(page.Boxes, loc[0], loc[1]),
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Start != out[j].Start {
+			return out[i].Start < out[j].Start
+		}
+		return out[i].Number < out[j].Number
+	})
+
+	return out, nil
+}
+
+// locate returns the positions of a number in text, ignoring occurrences
+// inside longer tokens such as a barcode holding the number.
+func locate(text, num string) [][2]int {
+	re := spaced(num)
+
+	var out [][2]int
+	for from := 0; from < len(text); {
+		loc := re.FindStringIndex(text[from:])
+		if loc == nil {
+			break
+		}
+		start, end := from+loc[0], from+loc[1]
+		if boundary(text, start, end) {
+			out = append(out, [2]int{start, end})
+			from = end
+			continue
+		}
+		_, n := utf8.DecodeRuneInString(text[start:])
+		from = start + n
+	}
+
+	return out
+}
+
+// boundary reports whether text[start:end] isn't preceded or followed by a
+// letter or digit.
+func boundary(text string, start, end int) bool {
+	if r, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isAlnum(r) {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isAlnum(r) {
+		return false
+	}
+
+	return true
+}
+
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// spaced matches a number with optional white space between characters,
+// as Find accepts for a page holding a single number.
+func spaced(num string) *regexp.Regexp {
+	parts := make([]string, 0, len(num))
+	for _, r := range num {
+		parts = append(parts, regexp.QuoteMeta(string(r)))
+	}
+
+	return regexp.MustCompile(`(?i)` + strings.Join(parts, `\s*`))
+}
+
+func overlapping(boxes []Box, start, end int) []Box {
+	var out []Box
+	for _, b := range boxes {
+		if b.Start < end && start < b.End {
+			out = append(out, b)
+		}
+	}
+
+	return out
+}
+
+// Pages returns a source of plain text pages.
+func Pages(texts ...string) TextSource {
+	return &pages{texts: texts}
+}
+
+type pages struct {
+	texts []string
+	next  int
+}
+
+func (p *pages) Next() (Page, error) {
+	if p.next >= len(p.texts) {
+		return Page{}, io.EOF
+	}
+	p.next++
+
+	return Page{Number: p.next, Text: p.texts[p.next-1]}, nil
+}
@@ -0,0 +1,96 @@
+package extract_test
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"dev.freespoke.com/go-package-tracking/extract"
+)
+
+func TestFindPages(t *testing.T) {
+	found, err := extract.Find(extract.Pages("Invoice 42", "UPS 1Z5R89390357567127, FedEx 986578788855", "again: 1Z5R89390357567127"))
+	if err != nil {
+		t.Fatalf("extract.Find() error %v", err)
+	}
+
+	var got []string
+	for _, m := range found {
+		got = append(got, fmt.Sprintf("%d:%s@%d-%d", m.Page, m.Number, m.Start, m.End))
+	}
+	want := []string{"2:1Z5R89390357567127@4-22", "2:986578788855@30-42", "3:1Z5R89390357567127@7-25"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extract.Find() = %v, expected %v", got, want)
+	}
+}
+
+func TestFindPageBoundaries(t *testing.T) {
+	found, err := extract.FindPage(extract.Page{Number: 1, Text: "barcode 9622001900000000000000776632517510 label 776632517510"})
+	if err != nil {
+		t.Fatalf("extract.FindPage() error %v", err)
+	}
+
+	var got []string
+	for _, m := range found {
+		got = append(got, fmt.Sprintf("%s@%d-%d", m.Number, m.Start, m.End))
+	}
+	want := []string{"9622001900000000000000776632517510@8-42", "776632517510@49-61"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extract.FindPage() = %v, expected %v", got, want)
+	}
+}
+
+// labels are the OCR words of two scanned pages.
+var labels = [][]extract.Box{
+	{
+		{Text: "1Z5R89390357567127", X: 60, Y: 212, Width: 300, Height: 20},
+		{Text: "SHIP", X: 10, Y: 100, Width: 40, Height: 20},
+		{Text: "UPS", X: 10, Y: 210, Width: 40, Height: 20},
+		{Text: "TO:", X: 55, Y: 102, Width: 30, Height: 20},
+	},
+	{
+		{Text: "0357567127", X: 200, Y: 50, Width: 150, Height: 20},
+		{Text: "1Z5R8939", X: 50, Y: 52, Width: 140, Height: 20},
+	},
+}
+
+func TestOCR(t *testing.T) {
+	ocr := extract.OCRFunc(func(page int) ([]extract.Box, error) {
+		if page > len(labels) {
+			return nil, io.EOF
+		}
+		return labels[page-1], nil
+	})
+
+	found, err := extract.Find(extract.NewOCRSource(ocr))
+	if err != nil {
+		t.Fatalf("extract.Find() error %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("extract.Find() = %+v, expected 2 matches", found)
+	}
+
+	first := found[0]
+	if first.Page != 1 || first.Number != "1Z5R89390357567127" || len(first.Boxes) != 1 || first.Boxes[0].X != 60 {
+		t.Errorf("extract.Find() page 1 = %+v", first)
+	}
+
+	// A number split in two words keeps both boxes.
+	second := found[1]
+	if second.Page != 2 || second.Number != "1Z5R89390357567127" || len(second.Boxes) != 2 {
+		t.Errorf("extract.Find() page 2 = %+v", second)
+	}
+}
+
+func TestLayout(t *testing.T) {
+	p := extract.Layout(1, labels[0])
+	if want := "SHIP TO:\nUPS 1Z5R89390357567127"; p.Text != want {
+		t.Errorf("extract.Layout() text = %q, expected %q", p.Text, want)
+	}
+	for _, b := range p.Boxes {
+		if p.Text[b.Start:b.End] != b.Text {
+			t.Errorf("extract.Layout() box %q at %d-%d", b.Text, b.Start, b.End)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package extract
+
+import (
+	"sort"
+	"strings"
+)
+
+// OCR is a hook for text recognition of scanned documents, such as a
+// wrapper around an OCR engine or service.
+type OCR interface {
+	// Recognize returns the words on a page, starting at 1, or io.EOF
+	// after the last page. Box offsets are ignored.
+	Recognize(page int) ([]Box, error)
+}
+
+// OCRFunc adapts a function to the OCR interface.
+type OCRFunc func(page int) ([]Box, error)
+
+func (f OCRFunc) Recognize(page int) ([]Box, error) {
+	return f(page)
+}
+
+// NewOCRSource returns a source of the pages recognized by ocr. The words
+// are laid out in lines from top to bottom and left to right, so matches
+// keep the boxes of their words.
+func NewOCRSource(ocr OCR) TextSource {
+	return &ocrSource{ocr: ocr}
+}
+
+type ocrSource struct {
+	ocr  OCR
+	page int
+}
+
+func (s *ocrSource) Next() (Page, error) {
+	boxes, err := s.ocr.Recognize(s.page + 1)
+	if err != nil {
+		return Page{}, err
+	}
+	s.page++
+
+	return Layout(s.page, boxes), nil
+}
+
+// Layout builds a page from positioned words. Words whose vertical centers
+// are within half a line height of a line's first word share the line.
+func Layout(number int, boxes []Box) Page {
+	sorted := make([]Box, 0, len(boxes))
+	for _, b := range boxes {
+		if strings.TrimSpace(b.Text) != "" {
+			sorted = append(sorted, b)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return center(sorted[i]) < center(sorted[j])
+	})
+
+	// Group the words in lines, then order each line.
+	var lines [][]Box
+	for _, b := range sorted {
+		if n := len(lines); n > 0 {
+			first := lines[n-1][0]
+			if center(b)-center(first) <= first.Height/2 {
+				lines[n-1] = append(lines[n-1], b)
+				continue
+			}
+		}
+		lines = append(lines, []Box{b})
+	}
+
+	page := Page{Number: number, Boxes: make([]Box, 0, len(sorted))}
+	var text strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			text.WriteByte('\n')
+		}
+		sort.SliceStable(line, func(i, j int) bool { return line[i].X < line[j].X })
+		for j, b := range line {
+			if j > 0 {
+				text.WriteByte(' ')
+			}
+			b.Start = text.Len()
+			text.WriteString(b.Text)
+			b.End = text.Len()
+			page.Boxes = append(page.Boxes, b)
+		}
+	}
+	page.Text = text.String()
+
+	return page
+}
+
+func center(b Box) float64 {
+	return b.Y + b.Height/2
+}
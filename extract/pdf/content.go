@@ -0,0 +1,369 @@
+package pdf
+
+import (
+	"io"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+// pageText returns the text of a page.
+func (d *document) pageText(page dict) string {
+	var data []byte
+	switch c := d.resolve(page["Contents"]).(type) {
+	case stream:
+		data, _ = d.decode(c)
+	case array:
+		// Content streams may split operators, so join them first.
+		for _, o := range c {
+			if s, ok := d.resolve(o).(stream); ok {
+				b, err := d.decode(s)
+				if err == nil {
+					data = append(append(data, b...), '\n')
+				}
+			}
+		}
+	}
+
+	w := &textWriter{fonts: make(map[object]*font), active: make(map[int]bool)}
+	res, _ := d.resolve(page["Resources"]).(dict)
+	d.run(data, res, w, 0)
+
+	return strings.TrimSpace(w.b.String())
+}
+
+// textWriter lays out shown text in lines.
+type textWriter struct {
+	b     strings.Builder
+	fonts map[object]*font
+	font  *font
+
+	y, lastY, leading float64
+	shown, space      bool
+
+	// Form XObjects being run and the number run so far.
+	active map[int]bool
+	forms  int
+}
+
+// show writes text, starting a new line if the baseline moved.
+func (w *textWriter) show(s string) {
+	if s == "" {
+		return
+	}
+	if w.shown {
+		switch {
+		case math.Abs(w.y-w.lastY) > 0.5:
+			w.b.WriteByte('\n')
+		case w.space:
+			w.gap()
+		}
+	}
+	w.b.WriteString(s)
+	w.shown, w.space, w.lastY = true, false, w.y
+}
+
+// gap writes a space unless the text already ends with white space.
+func (w *textWriter) gap() {
+	if s := w.b.String(); s != "" && !strings.HasSuffix(s, " ") && !strings.HasSuffix(s, "\n") {
+		w.b.WriteByte(' ')
+	}
+}
+
+// run interprets a content stream.
+func (d *document) run(data []byte, resources dict, w *textWriter, depth int) {
+	if depth > maxDepth {
+		return
+	}
+
+	var ops []object
+	l := &lexer{b: data}
+	for {
+		o, err := l.object()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			// Skip the bad token and its operands.
+			ops = ops[:0]
+			continue
+		}
+		op, ok := o.(keyword)
+		if !ok {
+			ops = append(ops, o)
+			continue
+		}
+
+		switch op {
+		case "BT":
+			w.y = 0
+		case "Tf":
+			if len(ops) >= 1 {
+				w.font = d.font(resources, ops[0], w)
+			}
+		case "TL":
+			if len(ops) >= 1 {
+				w.leading = number(ops[0])
+			}
+		case "Td", "TD":
+			if len(ops) >= 2 {
+				tx, ty := number(ops[0]), number(ops[1])
+				w.y += ty
+				if op == "TD" {
+					w.leading = -ty
+				}
+				if ty == 0 && tx != 0 {
+					w.space = true
+				}
+			}
+		case "Tm":
+			if len(ops) >= 6 {
+				w.y = number(ops[5])
+				w.space = true
+			}
+		case "T*":
+			w.y -= w.leading
+		case "Tj":
+			if len(ops) >= 1 {
+				w.show(w.font.text(ops[len(ops)-1]))
+			}
+		case "'", "\"":
+			w.y -= w.leading
+			if len(ops) >= 1 {
+				w.show(w.font.text(ops[len(ops)-1]))
+			}
+		case "TJ":
+			if len(ops) >= 1 {
+				a, _ := ops[len(ops)-1].(array)
+				for _, v := range a {
+					if s, ok := v.(string); ok {
+						w.show(w.font.text(s))
+					} else if number(v) < -250 {
+						// A wide negative adjustment separates words.
+						w.space = true
+					}
+				}
+			}
+		case "Do":
+			if len(ops) >= 1 {
+				d.form(resources, ops[0], w, depth)
+			}
+		case "BI":
+			l.skipImage()
+		}
+		ops = ops[:0]
+	}
+}
+
+// skipImage moves past the data of an inline image.
+func (l *lexer) skipImage() {
+	for {
+		o, err := l.object()
+		if err != nil {
+			return
+		}
+		if o == keyword("ID") {
+			break
+		}
+	}
+	for l.pos+2 < len(l.b) {
+		if isSpace(l.b[l.pos]) && l.b[l.pos+1] == 'E' && l.b[l.pos+2] == 'I' &&
+			(l.pos+3 == len(l.b) || isSpace(l.b[l.pos+3]) || isDelim(l.b[l.pos+3])) {
+			l.pos += 3
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.b)
+}
+
+// form runs a form XObject.
+func (d *document) form(resources dict, n object, w *textWriter, depth int) {
+	xobjects, _ := d.resolve(resources["XObject"]).(dict)
+	key, _ := n.(name)
+	if w.forms >= maxForms {
+		return
+	}
+
+	// A form drawing itself would never end.
+	if r, ok := xobjects[key].(ref); ok {
+		if w.active[r.num] {
+			return
+		}
+		w.active[r.num] = true
+		defer delete(w.active, r.num)
+	}
+
+	s, ok := d.resolve(xobjects[key]).(stream)
+	if !ok || s.hdr["Subtype"] != name("Form") {
+		return
+	}
+	w.forms++
+	data, err := d.decode(s)
+	if err != nil {
+		return
+	}
+
+	res, ok := d.resolve(s.hdr["Resources"]).(dict)
+	if !ok {
+		res = resources
+	}
+	d.run(data, res, w, depth+1)
+}
+
+func number(o object) float64 {
+	switch v := o.(type) {
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// font decodes the strings shown with a font.
+type font struct {
+	width int // bytes per character code
+	cmap  map[uint32]string
+}
+
+// font returns the font named in the resources.
+func (d *document) font(resources dict, n object, w *textWriter) *font {
+	fonts, _ := d.resolve(resources["Font"]).(dict)
+	key, _ := n.(name)
+	ref := fonts[key]
+	if f, ok := w.fonts[ref]; ok && ref != nil {
+		return f
+	}
+
+	fd, _ := d.resolve(ref).(dict)
+	f := &font{width: 1}
+	if fd["Subtype"] == name("Type0") {
+		f.width = 2
+	}
+	if s, ok := d.resolve(fd["ToUnicode"]).(stream); ok {
+		if data, err := d.decode(s); err == nil {
+			f.parseCMap(data)
+		}
+	}
+	if ref != nil {
+		w.fonts[ref] = f
+	}
+
+	return f
+}
+
+// text decodes a string operand.
+func (f *font) text(o object) string {
+	s, _ := o.(string)
+	if f == nil {
+		f = &font{width: 1}
+	}
+
+	var b strings.Builder
+	for i := 0; i+f.width <= len(s); i += f.width {
+		var code uint32
+		for _, c := range []byte(s[i : i+f.width]) {
+			code = code<<8 | uint32(c)
+		}
+		switch u, ok := f.cmap[code]; {
+		case ok:
+			b.WriteString(u)
+		case f.width == 1:
+			// Without a map, treat simple fonts as Latin-1.
+			b.WriteRune(rune(code))
+		}
+	}
+
+	return b.String()
+}
+
+// maxRange limits the codes mapped by a single bfrange.
+const maxRange = 1 << 16
+
+// parseCMap reads the code width and mappings of a ToUnicode CMap.
+func (f *font) parseCMap(data []byte) {
+	f.cmap = make(map[uint32]string)
+
+	var ops []object
+	l := &lexer{b: data}
+	for {
+		o, err := l.object()
+		if err != nil {
+			return
+		}
+		k, ok := o.(keyword)
+		if !ok {
+			ops = append(ops, o)
+			continue
+		}
+
+		switch k {
+		case "endcodespacerange":
+			if len(ops) >= 1 {
+				if s, ok := ops[0].(string); ok && len(s) > 0 {
+					f.width = len(s)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(ops); i += 2 {
+				src, _ := ops[i].(string)
+				dst, _ := ops[i+1].(string)
+				f.cmap[code(src)] = utf16BE(dst)
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(ops); i += 3 {
+				lo, _ := ops[i].(string)
+				hi, _ := ops[i+1].(string)
+				f.mapRange(code(lo), code(hi), ops[i+2])
+			}
+		}
+		ops = ops[:0]
+	}
+}
+
+func (f *font) mapRange(lo, hi uint32, dst object) {
+	if hi < lo || hi-lo >= maxRange {
+		return
+	}
+
+	switch v := dst.(type) {
+	case string:
+		// The last UTF-16 unit increments through the range.
+		units := utf16Units(v)
+		if len(units) == 0 {
+			return
+		}
+		// Loop on the offset so a range ending at 0xFFFFFFFF terminates.
+		for i := uint32(0); i <= hi-lo; i++ {
+			f.cmap[lo+i] = string(utf16.Decode(units))
+			units[len(units)-1]++
+		}
+	case array:
+		for i, d := range v {
+			if s, ok := d.(string); ok && uint32(i) <= hi-lo {
+				f.cmap[lo+uint32(i)] = utf16BE(s)
+			}
+		}
+	}
+}
+
+func code(s string) uint32 {
+	var c uint32
+	for _, b := range []byte(s) {
+		c = c<<8 | uint32(b)
+	}
+	return c
+}
+
+func utf16Units(s string) []uint16 {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return units
+}
+
+func utf16BE(s string) string {
+	return string(utf16.Decode(utf16Units(s)))
+}
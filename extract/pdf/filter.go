@@ -0,0 +1,169 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+// maxStream limits the decoded size of a stream.
+const maxStream = 64 << 20
+
+// decode applies the stream filters.
+func (d *document) decode(s stream) ([]byte, error) {
+	filters := d.resolve(s.hdr["Filter"])
+	params := d.resolve(s.hdr["DecodeParms"])
+
+	var names, parms array
+	switch f := filters.(type) {
+	case name:
+		names, parms = array{f}, array{params}
+	case array:
+		names = f
+		if p, ok := params.(array); ok {
+			parms = p
+		}
+	}
+
+	data := s.data
+	for i, f := range names {
+		var p dict
+		if i < len(parms) {
+			p, _ = d.resolve(parms[i]).(dict)
+		}
+
+		var err error
+		switch d.resolve(f) {
+		case name("FlateDecode"), name("Fl"):
+			data, err = inflate(data, p)
+		case name("ASCIIHexDecode"), name("AHx"):
+			data, err = asciiHex(data)
+		case name("ASCII85Decode"), name("A85"):
+			data, err = ascii85Decode(data)
+		default:
+			err = fmt.Errorf("%w %v", ErrFilter, f)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+func inflate(data []byte, p dict) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(io.LimitReader(zr, maxStream))
+	// Truncated streams are common; keep what was decoded.
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	if pred, _ := p["Predictor"].(int); pred >= 10 {
+		return unpredict(out, p)
+	}
+
+	return out, nil
+}
+
+// unpredict reverses PNG predictors.
+func unpredict(data []byte, p dict) ([]byte, error) {
+	colors, bits, columns := 1, 8, 1
+	if v, ok := p["Colors"].(int); ok && v > 0 {
+		colors = v
+	}
+	if v, ok := p["BitsPerComponent"].(int); ok && v > 0 {
+		bits = v
+	}
+	if v, ok := p["Columns"].(int); ok && v > 0 {
+		columns = v
+	}
+	bpp := (colors*bits + 7) / 8
+	row := (colors*bits*columns + 7) / 8
+
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, row)
+	for len(data) > row {
+		kind, cur := data[0], append([]byte(nil), data[1:row+1]...)
+		data = data[row+1:]
+		for i := range cur {
+			var left, up, upLeft byte
+			if i >= bpp {
+				left, upLeft = cur[i-bpp], prev[i-bpp]
+			}
+			up = prev[i]
+			switch kind {
+			case 1:
+				cur[i] += left
+			case 2:
+				cur[i] += up
+			case 3:
+				cur[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				cur[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, cur...)
+		prev = cur
+	}
+
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	default:
+		return c
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func asciiHex(data []byte) ([]byte, error) {
+	digits := make([]byte, 0, len(data))
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if !isSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	_, err := hex.Decode(out, digits)
+
+	return out, err
+}
+
+func ascii85Decode(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, 4*len(data)/5+4)
+	n, _, err := ascii85.Decode(out, data, true)
+
+	return out[:n], err
+}
@@ -0,0 +1,304 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+)
+
+var errSyntax = errors.New("pdf: syntax error")
+
+// PDF objects. Strings are Go strings of the raw bytes; numbers are int or
+// float64; null is nil.
+type (
+	object  = any
+	name    string
+	keyword string
+	array   []object
+	dict    map[name]object
+	ref     struct{ num, gen int }
+	stream  struct {
+		hdr  dict
+		data []byte // still encoded
+	}
+)
+
+func isSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// lexer reads objects from PDF syntax.
+type lexer struct {
+	b   []byte
+	pos int
+}
+
+// skip moves past white space and comments.
+func (l *lexer) skip() {
+	for l.pos < len(l.b) {
+		switch c := l.b[l.pos]; {
+		case isSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.b) && l.b[l.pos] != '\n' && l.b[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// object reads the next object. Keywords, including the closing ] and >>
+// delimiters, are returned as keyword values.
+func (l *lexer) object() (object, error) {
+	l.skip()
+	if l.pos >= len(l.b) {
+		return nil, io.EOF
+	}
+
+	switch c := l.b[l.pos]; {
+	case c == '/':
+		return l.name(), nil
+	case c == '(':
+		return l.literal()
+	case c == '<':
+		if l.peek(1) == '<' {
+			l.pos += 2
+			return l.dict()
+		}
+		return l.hex()
+	case c == '>':
+		if l.peek(1) == '>' {
+			l.pos += 2
+			return keyword(">>"), nil
+		}
+		l.pos++
+		return nil, errSyntax
+	case c == '[':
+		l.pos++
+		return l.array()
+	case c == ']' || c == '{' || c == '}' || c == ')':
+		l.pos++
+		return keyword(c), nil
+	case c == '+' || c == '-' || c == '.' || c >= '0' && c <= '9':
+		return l.number()
+	default:
+		start := l.pos
+		for l.pos < len(l.b) && !isSpace(l.b[l.pos]) && !isDelim(l.b[l.pos]) {
+			l.pos++
+		}
+		switch k := string(l.b[start:l.pos]); k {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			return keyword(k), nil
+		}
+	}
+}
+
+func (l *lexer) peek(n int) byte {
+	if l.pos+n < len(l.b) {
+		return l.b[l.pos+n]
+	}
+	return 0
+}
+
+func (l *lexer) name() name {
+	l.pos++ // '/'
+	var b []byte
+	for l.pos < len(l.b) && !isSpace(l.b[l.pos]) && !isDelim(l.b[l.pos]) {
+		c := l.b[l.pos]
+		if c == '#' && l.pos+2 < len(l.b) {
+			if v, err := strconv.ParseUint(string(l.b[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		l.pos++
+	}
+
+	return name(b)
+}
+
+// number reads an integer, a real or an "N G R" reference.
+func (l *lexer) number() (object, error) {
+	start := l.pos
+	real := false
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		if c == '.' {
+			real = true
+		} else if !(c >= '0' && c <= '9' || c == '+' || c == '-') {
+			break
+		}
+		l.pos++
+	}
+	s := string(l.b[start:l.pos])
+
+	if real {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0.0, nil
+		}
+		return f, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, nil
+	}
+
+	// Look ahead for a reference.
+	save := l.pos
+	l.skip()
+	gstart := l.pos
+	for l.pos < len(l.b) && l.b[l.pos] >= '0' && l.b[l.pos] <= '9' {
+		l.pos++
+	}
+	if l.pos > gstart {
+		gen, _ := strconv.Atoi(string(l.b[gstart:l.pos]))
+		l.skip()
+		if l.peek(0) == 'R' && (l.pos+1 >= len(l.b) || isSpace(l.b[l.pos+1]) || isDelim(l.b[l.pos+1])) {
+			l.pos++
+			return ref{n, gen}, nil
+		}
+	}
+	l.pos = save
+
+	return n, nil
+}
+
+func (l *lexer) literal() (object, error) {
+	l.pos++ // '('
+	var b []byte
+	depth := 1
+	for l.pos < len(l.b) {
+		c := l.b[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return string(b), nil
+			}
+		case '\\':
+			if l.pos >= len(l.b) {
+				return nil, errSyntax
+			}
+			c = l.b[l.pos]
+			l.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.peek(0) == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && l.peek(0) >= '0' && l.peek(0) <= '7'; i++ {
+						v = v*8 + int(l.b[l.pos]-'0')
+						l.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+
+	return nil, errSyntax
+}
+
+func (l *lexer) hex() (object, error) {
+	l.pos++ // '<'
+	end := bytes.IndexByte(l.b[l.pos:], '>')
+	if end < 0 {
+		return nil, errSyntax
+	}
+	digits := make([]byte, 0, end)
+	for _, c := range l.b[l.pos : l.pos+end] {
+		if !isSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	l.pos += end + 1
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	b := make([]byte, len(digits)/2)
+	for i := range b {
+		v, err := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		if err != nil {
+			return nil, errSyntax
+		}
+		b[i] = byte(v)
+	}
+
+	return string(b), nil
+}
+
+func (l *lexer) array() (object, error) {
+	var a array
+	for {
+		o, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		if o == keyword("]") {
+			return a, nil
+		}
+		a = append(a, o)
+	}
+}
+
+func (l *lexer) dict() (object, error) {
+	d := make(dict)
+	for {
+		k, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		if k == keyword(">>") {
+			return d, nil
+		}
+		key, ok := k.(name)
+		if !ok {
+			return nil, errSyntax
+		}
+		v, err := l.object()
+		if err != nil {
+			return nil, err
+		}
+		d[key] = v
+	}
+}
@@ -0,0 +1,376 @@
+// Package pdf reads the text layer of PDF documents.
+//
+// It handles the common output of invoicing and label software: compressed
+// and object streams, incremental updates, simple and composite fonts with
+// ToUnicode maps, and form XObjects. Encrypted documents and scanned pages
+// without text aren't supported; use an extract.OCR hook for those.
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+
+	"dev.freespoke.com/go-package-tracking/extract"
+)
+
+var (
+	ErrNotPDF    = errors.New("pdf: not a PDF document")
+	ErrEncrypted = errors.New("pdf: encrypted documents are not supported")
+	ErrNoPages   = errors.New("pdf: no pages")
+	ErrFilter    = errors.New("pdf: unsupported filter")
+)
+
+const (
+	// maxDepth limits nested page trees and form XObjects.
+	maxDepth = 32
+
+	// maxPages limits the pages of a document.
+	maxPages = 1 << 16
+
+	// maxForms limits the form XObjects run for a page, as a form may be
+	// drawn many times.
+	maxForms = 1 << 12
+)
+
+// Reader reads the text of a document page by page. It is an
+// extract.TextSource.
+type Reader struct {
+	doc   *document
+	pages []dict
+	next  int
+}
+
+// NewReader reads a document.
+func NewReader(r io.Reader) (*Reader, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if i := bytes.Index(b, []byte("%PDF-")); i < 0 || i > 1024 {
+		return nil, ErrNotPDF
+	}
+
+	d := newDocument(b)
+	for _, t := range d.trailers {
+		if _, ok := t["Encrypt"]; ok {
+			return nil, ErrEncrypted
+		}
+	}
+
+	root := d.root()
+	if root == nil {
+		return nil, ErrNoPages
+	}
+	pages, _ := d.resolve(root["Pages"]).(dict)
+	var out []dict
+	d.pageList(pages, nil, map[int]bool{}, 0, &out)
+	if len(out) == 0 {
+		return nil, ErrNoPages
+	}
+
+	return &Reader{doc: d, pages: out}, nil
+}
+
+// NumPages returns the number of pages.
+func (r *Reader) NumPages() int {
+	return len(r.pages)
+}
+
+// Page returns the text of a page, starting at 1.
+func (r *Reader) Page(n int) (extract.Page, error) {
+	if n < 1 || n > len(r.pages) {
+		return extract.Page{}, fmt.Errorf("pdf: no page %d", n)
+	}
+
+	return extract.Page{Number: n, Text: r.doc.pageText(r.pages[n-1])}, nil
+}
+
+// Next returns the next page, or io.EOF after the last one.
+func (r *Reader) Next() (extract.Page, error) {
+	if r.next >= len(r.pages) {
+		return extract.Page{}, io.EOF
+	}
+	r.next++
+
+	return r.Page(r.next)
+}
+
+// document indexes the objects of a file. Cross-reference tables are
+// ignored in favour of scanning the file, which also recovers damaged ones.
+type document struct {
+	b        []byte
+	objects  map[int]location
+	trailers []dict
+
+	cache   map[int]object
+	decoded map[int][]byte // object streams
+}
+
+// location is where an object is defined: at an offset in the file, or at
+// an index in an object stream.
+type location struct {
+	offset int
+	stream int
+	index  int
+}
+
+var objHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+func newDocument(b []byte) *document {
+	d := &document{
+		b:       b,
+		objects: make(map[int]location),
+		cache:   make(map[int]object),
+		decoded: make(map[int][]byte),
+	}
+
+	// Later definitions replace earlier ones, as in incremental updates.
+	for pos := 0; pos < len(b); {
+		loc := objHeader.FindSubmatchIndex(b[pos:])
+		if loc == nil {
+			break
+		}
+
+		start := pos + loc[0]
+		num := atoi(b[pos+loc[2] : pos+loc[3]])
+		l := &lexer{b: b, pos: pos + loc[1]}
+		o, err := d.parseBody(l)
+		pos = l.pos
+		if err != nil {
+			pos = start + loc[1] - loc[0]
+			continue
+		}
+		d.objects[num] = location{offset: start, stream: -1}
+
+		s, ok := o.(stream)
+		if !ok {
+			continue
+		}
+		switch s.hdr["Type"] {
+		case name("XRef"):
+			d.trailers = append(d.trailers, s.hdr)
+		case name("ObjStm"):
+			d.indexObjStm(num, s)
+		}
+	}
+
+	for pos := 0; ; {
+		t := bytes.Index(b[pos:], []byte("trailer"))
+		if t < 0 {
+			break
+		}
+		l := &lexer{b: b, pos: pos + t + len("trailer")}
+		if o, err := l.object(); err == nil {
+			if td, ok := o.(dict); ok {
+				d.trailers = append(d.trailers, td)
+			}
+		}
+		pos += t + len("trailer")
+	}
+
+	return d
+}
+
+// parseBody reads an object after its "N G obj" header.
+func (d *document) parseBody(l *lexer) (object, error) {
+	o, err := l.object()
+	if err != nil {
+		return nil, err
+	}
+	hdr, ok := o.(dict)
+	if !ok {
+		return o, nil
+	}
+
+	save := l.pos
+	if k, err := l.object(); err != nil || k != keyword("stream") {
+		l.pos = save
+		return hdr, nil
+	}
+
+	// The data starts after the end of line following the keyword.
+	if l.peek(0) == '\r' {
+		l.pos++
+	}
+	if l.peek(0) == '\n' {
+		l.pos++
+	}
+	start := l.pos
+
+	end := -1
+	if n, ok := hdr["Length"].(int); ok && n >= 0 && start+n <= len(l.b) {
+		rest := bytes.TrimLeft(l.b[start+n:], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			end = start + n
+		}
+	}
+	if end < 0 {
+		i := bytes.Index(l.b[start:], []byte("endstream"))
+		if i < 0 {
+			return nil, errSyntax
+		}
+		end = start + i
+		for end > start && (l.b[end-1] == '\n' || l.b[end-1] == '\r') {
+			end--
+		}
+	}
+	l.pos = end + bytes.Index(l.b[end:], []byte("endstream")) + len("endstream")
+
+	return stream{hdr: hdr, data: l.b[start:end]}, nil
+}
+
+// indexObjStm records the objects of an object stream.
+func (d *document) indexObjStm(num int, s stream) {
+	data, err := d.decode(s)
+	if err != nil {
+		return
+	}
+	d.decoded[num] = data
+
+	n, _ := s.hdr["N"].(int)
+	l := &lexer{b: data}
+	for i := 0; i < n; i++ {
+		o, err := l.object()
+		if err != nil {
+			return
+		}
+		objNum, ok := o.(int)
+		if !ok {
+			return
+		}
+		if _, err := l.object(); err != nil {
+			return
+		}
+		d.objects[objNum] = location{stream: num, index: i}
+	}
+}
+
+// object returns an object by number, or nil.
+func (d *document) object(num int) object {
+	if o, ok := d.cache[num]; ok {
+		return o
+	}
+	d.cache[num] = nil // breaks reference cycles
+
+	loc, ok := d.objects[num]
+	if !ok {
+		return nil
+	}
+
+	var o object
+	if loc.stream < 0 {
+		l := &lexer{b: d.b, pos: loc.offset}
+		l.object() // number
+		l.object() // generation
+		l.object() // obj
+		o, _ = d.parseBody(l)
+	} else {
+		o = d.streamObject(loc)
+	}
+	d.cache[num] = o
+
+	return o
+}
+
+// streamObject reads an object from an object stream.
+func (d *document) streamObject(loc location) object {
+	s, ok := d.object(loc.stream).(stream)
+	data := d.decoded[loc.stream]
+	if !ok || data == nil {
+		return nil
+	}
+
+	first, _ := s.hdr["First"].(int)
+	l := &lexer{b: data}
+	var offset int
+	for i := 0; i <= loc.index; i++ {
+		l.object()
+		o, _ := l.object()
+		offset, _ = o.(int)
+	}
+	if first+offset >= len(data) {
+		return nil
+	}
+	l.pos = first + offset
+	o, _ := l.object()
+
+	return o
+}
+
+// resolve follows references.
+func (d *document) resolve(o object) object {
+	for i := 0; i < maxDepth; i++ {
+		r, ok := o.(ref)
+		if !ok {
+			return o
+		}
+		o = d.object(r.num)
+	}
+
+	return nil
+}
+
+// root returns the document catalog.
+func (d *document) root() dict {
+	for i := len(d.trailers) - 1; i >= 0; i-- {
+		if root, ok := d.resolve(d.trailers[i]["Root"]).(dict); ok {
+			return root
+		}
+	}
+
+	// Damaged files may lack a trailer.
+	var root dict
+	for num := range d.objects {
+		if o, ok := d.object(num).(dict); ok && o["Type"] == name("Catalog") {
+			root = o
+		}
+	}
+
+	return root
+}
+
+// pageList adds the pages under a page tree node to out, with inherited
+// resources copied in. Nodes are visited once, so trees listing a node
+// twice or containing themselves still end.
+func (d *document) pageList(node dict, resources object, seen map[int]bool, depth int, out *[]dict) {
+	if node == nil || depth > maxDepth || len(*out) >= maxPages {
+		return
+	}
+
+	if r, ok := node["Resources"]; ok {
+		resources = r
+	}
+
+	kids, ok := d.resolve(node["Kids"]).(array)
+	if !ok || node["Type"] == name("Page") {
+		page := make(dict, len(node)+1)
+		for k, v := range node {
+			page[k] = v
+		}
+		page["Resources"] = resources
+		*out = append(*out, page)
+		return
+	}
+
+	for _, k := range kids {
+		if r, ok := k.(ref); ok {
+			if seen[r.num] {
+				continue
+			}
+			seen[r.num] = true
+		}
+		kid, _ := d.resolve(k).(dict)
+		d.pageList(kid, resources, seen, depth+1, out)
+	}
+}
+
+func atoi(b []byte) int {
+	n := 0
+	for _, c := range b {
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
@@ -0,0 +1,299 @@
+package pdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"dev.freespoke.com/go-package-tracking/extract"
+	"dev.freespoke.com/go-package-tracking/extract/pdf"
+)
+
+// build writes a PDF with the objects, numbered from 1, and a trailer.
+func build(objects []string, trailer string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer, xref)
+
+	return b.Bytes()
+}
+
+func plain(data string) string {
+	return fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(data), data)
+}
+
+func flate(hdr, data string) string {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write([]byte(data))
+	w.Close()
+
+	return fmt.Sprintf("<< /Length %d /Filter /FlateDecode %s >>\nstream\n%s\nendstream", b.Len(), hdr, b.String())
+}
+
+// codes encodes text for the Type0 test font, which maps 2-byte codes
+// 0x0100 + c to c.
+func codes(s string) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, c := range s {
+		fmt.Fprintf(&b, "%04X", 0x100+c)
+	}
+	b.WriteByte('>')
+
+	return b.String()
+}
+
+const cmap = `/CIDInit /ProcSet findresource begin
+12 dict begin
+begincmap
+1 begincodespacerange
+<0000> <FFFF>
+endcodespacerange
+1 beginbfrange
+<0130> <0139> <0030>
+endbfrange
+6 beginbfchar
+<0120> <0020>
+<0146> <0046>
+<0165> <0065>
+<0164> <0064>
+<0145> <0045>
+<0178> <0078>
+endbfchar
+endcmap
+CMapName currentdict /CMap defineresource pop
+end
+end`
+
+func testPDF() []byte {
+	font := "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"
+	objStm := "4 0 " + font
+
+	return build([]string{
+		/* 1 */ "<< /Type /Catalog /Pages 2 0 R >>",
+		/* 2 */ "<< /Type /Pages /Kids [3 0 R 6 0 R] /Count 2 /Resources << /Font << /F1 4 0 R >> >> >>",
+		/* 3 */ "<< /Type /Page /Parent 2 0 R /Contents 5 0 R >>",
+		/* 4 */ "null",
+		/* 5 */ plain("BT /F1 12 Tf 72 720 Td (Invoice \\(June\\)) Tj 0 -14 Td (UPS) Tj 40 0 Td (1Z5R89390357567127) Tj ET"),
+		/* 6 */ "<< /Type /Page /Parent 2 0 R /Contents [7 0 R 8 0 R] /Resources << /Font << /F2 9 0 R >> /XObject << /X1 11 0 R >> >> >>",
+		/* 7 */ flate("", "BT /F2 10 Tf 1 0 0 1 72 700 Tm ["+codes("FedEx")+" -300 "+codes("9865787")),
+		/* 8 */ plain("-100 " + codes("88855") + "] TJ ET BI /W 1 /H 1 ID \x00EI\xff EI /X1 Do"),
+		/* 9 */ "<< /Type /Font /Subtype /Type0 /BaseFont /Invoice /Encoding /Identity-H /ToUnicode 10 0 R >>",
+		/* 10 */ flate("", cmap),
+		/* 11 */ "<< /Type /XObject /Subtype /Form /BBox [0 0 612 792] /Resources << /Font << /F1 4 0 R >> >> /Length 44 >>\nstream\nBT /F1 9 Tf 72 100 Td (Ref: RB123456785GB) Tj ET\nendstream",
+		/* 12 */ flate(fmt.Sprintf("/Type /ObjStm /N 1 /First %d", len("4 0 ")), objStm),
+	}, "<< /Root 1 0 R /Size 13 >>")
+}
+
+func TestReader(t *testing.T) {
+	r, err := pdf.NewReader(bytes.NewReader(testPDF()))
+	if err != nil {
+		t.Fatalf("pdf.NewReader() error %v", err)
+	}
+	if n := r.NumPages(); n != 2 {
+		t.Fatalf("pdf.Reader.NumPages() = %d, expected 2", n)
+	}
+
+	want := []string{
+		"Invoice (June)\nUPS 1Z5R89390357567127",
+		"FedEx 986578788855\nRef: RB123456785GB",
+	}
+	for i, w := range want {
+		p, err := r.Next()
+		if err != nil {
+			t.Fatalf("pdf.Reader.Next() error %v", err)
+		}
+		if p.Number != i+1 || p.Text != w {
+			t.Errorf("pdf.Reader.Next() = %d %q, expected %d %q", p.Number, p.Text, i+1, w)
+		}
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("pdf.Reader.Next() error %v, expected io.EOF", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	r, err := pdf.NewReader(bytes.NewReader(testPDF()))
+	if err != nil {
+		t.Fatalf("pdf.NewReader() error %v", err)
+	}
+
+	found, err := extract.Find(r)
+	if err != nil {
+		t.Fatalf("extract.Find() error %v", err)
+	}
+
+	var got []string
+	for _, m := range found {
+		got = append(got, fmt.Sprintf("%d:%s", m.Page, m.Number))
+	}
+	want := "1:1Z5R89390357567127 2:986578788855 2:RB123456785GB"
+	if strings.Join(got, " ") != want {
+		t.Errorf("extract.Find() = %v, expected %s", got, want)
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want error
+	}{
+		{"not pdf", []byte("hello"), pdf.ErrNotPDF},
+		{"encrypted", build([]string{"<< /Type /Catalog >>"}, "<< /Root 1 0 R /Encrypt << /Filter /Standard >> >>"), pdf.ErrEncrypted},
+		{"no pages", build([]string{"<< /Type /Catalog /Pages 2 0 R >>", "<< /Type /Pages /Kids [] /Count 0 >>"}, "<< /Root 1 0 R >>"), pdf.ErrNoPages},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := pdf.NewReader(bytes.NewReader(tt.in)); !errors.Is(err, tt.want) {
+				t.Errorf("pdf.NewReader() error %v, expected %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// readAll reads every page, failing if that takes too long.
+func readAll(t *testing.T, b []byte) []string {
+	t.Helper()
+
+	type result struct {
+		pages []string
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		r, err := pdf.NewReader(bytes.NewReader(b))
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		var pages []string
+		for {
+			p, err := r.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				done <- result{err: err}
+				return
+			}
+			pages = append(pages, p.Text)
+		}
+		done <- result{pages: pages}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			t.Fatalf("pdf.Reader error %v", res.err)
+		}
+		return res.pages
+	case <-time.After(5 * time.Second):
+		t.Fatal("pdf.Reader didn't finish")
+		return nil
+	}
+}
+
+func TestCMapRangeEnd(t *testing.T) {
+	cmap := "begincmap 1 begincodespacerange <00000000> <FFFFFFFF> endcodespacerange " +
+		"1 beginbfrange <FFFFFFF0> <FFFFFFFF> <0041> endbfrange endcmap"
+	b := build([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		plain("BT /F1 12 Tf <FFFFFFF1FFFFFFFF> Tj ET"),
+		"<< /Type /Font /Subtype /Type0 /ToUnicode 6 0 R >>",
+		plain(cmap),
+	}, "<< /Root 1 0 R >>")
+
+	if got := readAll(t, b); !reflect.DeepEqual(got, []string{"BP"}) {
+		t.Errorf("pdf.Reader pages = %q, expected [BP]", got)
+	}
+}
+
+func TestPageTreeLoop(t *testing.T) {
+	b := build([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [2 0 R 2 0 R 3 0 R 3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		plain("BT /F1 12 Tf (UPS 1Z5R89390357567127) Tj ET"),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}, "<< /Root 1 0 R >>")
+
+	if got := readAll(t, b); !reflect.DeepEqual(got, []string{"UPS 1Z5R89390357567127"}) {
+		t.Errorf("pdf.Reader pages = %q", got)
+	}
+}
+
+// forms returns a page drawing form XObject 4, where each of n forms draws
+// the next one three times and the last draws itself.
+func forms(n int) []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /XObject << /X 5 0 R >> >> >>",
+		plain("/X Do"),
+	}
+	for i := 0; i < n; i++ {
+		next := 5 + i + 1
+		if i == n-1 {
+			next = 5 + i
+		}
+		data := "/X Do /X Do /X Do BT (x) Tj ET"
+		objects = append(objects, fmt.Sprintf("<< /Type /XObject /Subtype /Form /Resources << /XObject << /X %d 0 R >> >> /Length %d >>\nstream\n%s\nendstream", next, len(data), data))
+	}
+
+	return build(objects, "<< /Root 1 0 R >>")
+}
+
+func TestFormLoop(t *testing.T) {
+	for _, n := range []int{1, 40} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			got := readAll(t, forms(n))
+			if len(got) != 1 || !strings.HasPrefix(got[0], "x") {
+				t.Errorf("pdf.Reader pages = %.40q", got)
+			}
+		})
+	}
+}
+
+func FuzzReader(f *testing.F) {
+	f.Add(testPDF())
+	f.Add(forms(3))
+	f.Add(build([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [2 0 R 2 0 R 3 0 R] >>",
+		"<< /Type /Page /Contents 4 0 R >>",
+		plain("BT (1Z5R89390357567127) Tj ET"),
+	}, "<< /Root 1 0 R >>"))
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		r, err := pdf.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return
+		}
+		for {
+			if _, err := r.Next(); err != nil {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,354 @@
+package parcel
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// defaultAttributes are the attributes FindHTML scans by default.
+var defaultAttributes = []string{"href", "value", "data-*"}
+
+// htmlBlocks are the elements that end a run of text. Numbers may be split
+// across other elements, such as <b>1Z</b>999.
+var htmlBlocks = map[string]bool{
+	"address": true, "article": true, "aside": true, "blockquote": true,
+	"br": true, "caption": true, "dd": true, "div": true, "dl": true,
+	"dt": true, "fieldset": true, "figure": true, "footer": true,
+	"form": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hr": true, "li": true,
+	"main": true, "nav": true, "ol": true, "p": true, "pre": true,
+	"section": true, "table": true, "tbody": true, "td": true,
+	"tfoot": true, "th": true, "thead": true, "tr": true, "ul": true,
+}
+
+// htmlVoid are the elements without an end tag.
+var htmlVoid = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true,
+	"hr": true, "img": true, "input": true, "link": true, "meta": true,
+	"param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlSkip are the elements whose content isn't scanned.
+var htmlSkip = map[string]bool{"head": true, "script": true, "style": true, "template": true}
+
+// HTMLMatch is a tracking number found in an HTML document.
+type HTMLMatch struct {
+	// Number is the matched text without surrounding punctuation.
+	Number   string
+	Tracking []Tracking
+
+	// Path is the element holding the number in the source markup, such as
+	// "/html/body/table/tr[2]/td[2]". Indexes count same-named siblings and
+	// are left out for the first one. A number split across elements is
+	// held by their closest common ancestor.
+	Path string
+
+	// Attr is the attribute holding the number, empty for text.
+	Attr string
+
+	// Start and End are the byte offsets of the number in the document.
+	// Inside text with character references they widen to the text node.
+	Start, End int
+}
+
+// WithAttributes makes FindHTML scan the named attributes instead of href,
+// value and data-*. A trailing "*" matches a prefix.
+func WithAttributes(names ...string) Option {
+	return func(f *filter) { f.attributes = append(f.attributes, names...) }
+}
+
+// FindHTML extracts tracking numbers from an HTML document. It scans the
+// text of the document, ignoring markup, and the href, value and data-*
+// attributes of its elements. Results are ordered by position. Options
+// restrict the services checked, as with Find.
+func FindHTML(doc string, opts ...Option) ([]HTMLMatch, error) {
+	return defaultRegistry.FindHTML(doc, opts...)
+}
+
+// FindHTML extracts tracking numbers from an HTML document among the
+// registry services. See FindHTML.
+func (r *Registry) FindHTML(doc string, opts ...Option) ([]HTMLMatch, error) {
+	f := newFilter(opts)
+	attrs := r.attributes(f)
+
+	var (
+		out   []HTMLMatch
+		run   htmlRun
+		stack = []*htmlElement{{}}
+		skip  int
+		pos   int
+	)
+
+	flush := func() error {
+		m, err := run.find(r, opts)
+		out = append(out, m...)
+		run = htmlRun{}
+		return err
+	}
+
+	z := html.NewTokenizer(strings.NewReader(doc))
+	for {
+		tt := z.Next()
+		raw := string(z.Raw())
+		start := pos
+		pos += len(raw)
+
+		switch tt {
+		case html.ErrorToken:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			sort.SliceStable(out, func(i, j int) bool { return out[i].Start < out[j].Start })
+			return out, nil
+
+		case html.TextToken:
+			if skip == 0 {
+				run.add(string(z.Text()), raw, start, stack[len(stack)-1])
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if htmlBlocks[tok.Data] {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+
+			// The body ends a head left open.
+			if tok.Data == "body" {
+				stack, skip = closeElement(stack, skip, "head")
+			}
+
+			el := stack[len(stack)-1].child(tok.Data)
+			if tt == html.StartTagToken && !htmlVoid[tok.Data] {
+				stack = append(stack, el)
+				if htmlSkip[tok.Data] {
+					skip++
+				}
+			}
+			if skip > 0 {
+				continue
+			}
+
+			for _, a := range tok.Attr {
+				if !matchAttribute(attrs, a.Key) {
+					continue
+				}
+				m, err := r.findAttribute(a, raw, start, el.path, opts)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, m...)
+			}
+
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			if htmlBlocks[string(name)] {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			stack, skip = closeElement(stack, skip, string(name))
+		}
+	}
+}
+
+// closeElement pops the innermost open element with the name and any
+// unclosed elements inside it.
+func closeElement(stack []*htmlElement, skip int, name string) ([]*htmlElement, int) {
+	for i := len(stack) - 1; i > 0; i-- {
+		if stack[i].name == name {
+			for _, el := range stack[i:] {
+				if htmlSkip[el.name] {
+					skip--
+				}
+			}
+			return stack[:i], skip
+		}
+	}
+
+	return stack, skip
+}
+
+// attributes returns the attributes scanned by FindHTML for the call
+// options, falling back to the registry options.
+func (r *Registry) attributes(f *filter) []string {
+	if f != nil && f.attributes != nil {
+		return f.attributes
+	}
+	if r.filter != nil && r.filter.attributes != nil {
+		return r.filter.attributes
+	}
+
+	return defaultAttributes
+}
+
+func matchAttribute(names []string, key string) bool {
+	for _, n := range names {
+		if p := strings.TrimSuffix(n, "*"); p != n && strings.HasPrefix(key, p) || n == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findAttribute finds the numbers in an attribute of the tag at start.
+func (r *Registry) findAttribute(a html.Attribute, tag string, start int, path string, opts []Option) ([]HTMLMatch, error) {
+	res, err := r.Find(a.Val, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]HTMLMatch, 0, len(res))
+	for term, t := range res {
+		m := HTMLMatch{Number: TrimTerm(term), Tracking: t, Path: path, Attr: a.Key, Start: start, End: start + len(tag)}
+		if i := strings.Index(tag, m.Number); i >= 0 {
+			m.Start, m.End = start+i, start+i+len(m.Number)
+		}
+		out = append(out, m)
+	}
+
+	return out, nil
+}
+
+// htmlElement is an open element of the document.
+type htmlElement struct {
+	name     string
+	path     string
+	children map[string]int
+}
+
+// child returns the next child element with the name.
+func (e *htmlElement) child(name string) *htmlElement {
+	if e.children == nil {
+		e.children = make(map[string]int)
+	}
+	e.children[name]++
+
+	path := e.path + "/" + name
+	if n := e.children[name]; n > 1 {
+		path += "[" + strconv.Itoa(n) + "]"
+	}
+
+	return &htmlElement{name: name, path: path}
+}
+
+// htmlRun is the text between two block boundaries.
+type htmlRun struct {
+	text strings.Builder
+	segs []htmlSegment
+}
+
+// htmlSegment is a text node of a run.
+type htmlSegment struct {
+	at         int // offset in the run text
+	start, end int // offsets in the document
+	text, raw  string
+	path       string
+}
+
+func (r *htmlRun) add(text, raw string, start int, el *htmlElement) {
+	r.segs = append(r.segs, htmlSegment{
+		at:    r.text.Len(),
+		start: start,
+		end:   start + len(raw),
+		text:  text,
+		raw:   raw,
+		path:  el.path,
+	})
+	r.text.WriteString(text)
+}
+
+// find runs Find over the text and maps the numbers back to the document.
+func (r *htmlRun) find(reg *Registry, opts []Option) ([]HTMLMatch, error) {
+	text := r.text.String()
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	res, err := reg.Find(text, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []HTMLMatch
+	for term, t := range res {
+		num := TrimTerm(term)
+
+		found := false
+		for from := 0; ; {
+			i := strings.Index(text[from:], num)
+			if i < 0 {
+				break
+			}
+			i += from
+			from = i + len(num)
+			found = true
+
+			first, last := r.segment(i), r.segment(from-1)
+			out = append(out, HTMLMatch{
+				Number:   num,
+				Tracking: t,
+				Path:     commonPath(first.path, last.path),
+				Start:    first.offset(i, false),
+				End:      last.offset(from, true),
+			})
+		}
+
+		// Numbers joined across white space don't appear as is.
+		if !found {
+			first, last := r.segs[0], r.segs[len(r.segs)-1]
+			out = append(out, HTMLMatch{
+				Number:   num,
+				Tracking: t,
+				Path:     commonPath(first.path, last.path),
+				Start:    first.start,
+				End:      last.end,
+			})
+		}
+	}
+
+	return out, nil
+}
+
+// segment returns the segment holding a run offset.
+func (r *htmlRun) segment(at int) htmlSegment {
+	i := sort.Search(len(r.segs), func(i int) bool { return r.segs[i].at+len(r.segs[i].text) > at })
+	if i == len(r.segs) {
+		i--
+	}
+
+	return r.segs[i]
+}
+
+// offset maps a run offset within the segment to the document. Offsets
+// past character references are mapped when the text before or after them
+// is unchanged, otherwise they widen to the segment.
+func (s htmlSegment) offset(at int, end bool) int {
+	n := at - s.at
+	switch {
+	case s.text[:n] == s.raw[:minInt(n, len(s.raw))]:
+		return s.start + n
+	case len(s.text)-n <= len(s.raw) && s.text[n:] == s.raw[len(s.raw)-(len(s.text)-n):]:
+		return s.end - (len(s.text) - n)
+	case end:
+		return s.end
+	default:
+		return s.start
+	}
+}
+
+// commonPath returns the closest common ancestor of two element paths.
+func commonPath(a, b string) string {
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+	n := 0
+	for n < len(as) && n < len(bs) && as[n] == bs[n] {
+		n++
+	}
+
+	return strings.Join(as[:n], "/")
+}
@@ -0,0 +1,70 @@
+package parcel_test
+
+import (
+	"reflect"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+const shippingHTML = `<html><head><title>RB123456785GB</title></head>
+<body>
+<table>
+<tr><td>Order</td><td>42</td></tr>
+<tr><td>Tracking</td><td>1Z5R89390357567127</td></tr>
+</table>
+<p>FedEx: <b>9865</b><span>7878</span>8855.</p>
+<p><a href="https://tools.usps.com/go/TrackConfirmAction?tLabels=9400111201080805483016">Track</a>
+<input type="hidden" value="RB123456785GB"><span data-tracking="7035114477138472" title="9361289878700317633795"></span></p>
+<p>Canada&nbsp;Post 7035114477138472</p>
+<p>USPS 9361&#50;89878700317633795 delivered</p>
+<script>var n = "1Z5R89390357567127";</script>
+</body></html>`
+
+func TestFindHTML(t *testing.T) {
+	res, err := parcel.FindHTML(shippingHTML)
+	if err != nil {
+		t.Fatalf("parcel.FindHTML() error %v", err)
+	}
+
+	type match struct {
+		Number, Path, Attr, Source string
+	}
+	want := []match{
+		{"1Z5R89390357567127", "/html/body/table/tr[2]/td[2]", "", "1Z5R89390357567127"},
+		{"986578788855", "/html/body/p", "", "9865</b><span>7878</span>8855"},
+		{"9400111201080805483016", "/html/body/p[2]/a", "href", "9400111201080805483016"},
+		{"RB123456785GB", "/html/body/p[2]/input", "value", "RB123456785GB"},
+		{"7035114477138472", "/html/body/p[2]/span", "data-tracking", "7035114477138472"},
+		{"7035114477138472", "/html/body/p[3]", "", "7035114477138472"},
+		{"9361289878700317633795", "/html/body/p[4]", "", "9361&#50;89878700317633795"},
+	}
+
+	got := make([]match, 0, len(res))
+	for _, m := range res {
+		if len(m.Tracking) == 0 {
+			t.Errorf("parcel.FindHTML() %s has no results", m.Number)
+		}
+		got = append(got, match{m.Number, m.Path, m.Attr, shippingHTML[m.Start:m.End]})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parcel.FindHTML() =\n%+v\nexpected\n%+v", got, want)
+	}
+}
+
+func TestFindHTMLAttributes(t *testing.T) {
+	res, err := parcel.FindHTML(shippingHTML, parcel.WithAttributes("title"))
+	if err != nil {
+		t.Fatalf("parcel.FindHTML() error %v", err)
+	}
+
+	var attrs []string
+	for _, m := range res {
+		if m.Attr != "" {
+			attrs = append(attrs, m.Attr+"="+m.Number)
+		}
+	}
+	if want := []string{"title=9361289878700317633795"}; !reflect.DeepEqual(attrs, want) {
+		t.Errorf("parcel.FindHTML() attributes = %v, expected %v", attrs, want)
+	}
+}
@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"errors"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// maxRepeat is the most extra repetitions generated for an unbounded
+// repetition such as * or +.
+const maxRepeat = 2
+
+// Generate returns a random string matched by the regex. Named groups with
+// values in groups are filled with one of those values instead, so lookups
+// such as country codes can be satisfied. Lookaheads are ignored.
+func (r RegexParser) Generate(rnd *rand.Rand, groups map[string][]string) (string, error) {
+	if r.Regex == nil {
+		return "", errors.New("no regex")
+	}
+
+	re, err := syntax.Parse(r.Regex.String(), syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	generate(&b, re, rnd, groups)
+
+	return b.String(), nil
+}
+
+func generate(b *strings.Builder, re *syntax.Regexp, rnd *rand.Rand, groups map[string][]string) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(pickRune(re.Rune, rnd))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		const chars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+		b.WriteByte(chars[rnd.Intn(len(chars))])
+	case syntax.OpCapture:
+		if vals := groups[re.Name]; len(vals) > 0 {
+			b.WriteString(vals[rnd.Intn(len(vals))])
+			return
+		}
+		generate(b, re.Sub[0], rnd, groups)
+	case syntax.OpStar:
+		repeat(b, re.Sub[0], rnd, groups, 0, maxRepeat)
+	case syntax.OpPlus:
+		repeat(b, re.Sub[0], rnd, groups, 1, 1+maxRepeat)
+	case syntax.OpQuest:
+		repeat(b, re.Sub[0], rnd, groups, 0, 1)
+	case syntax.OpRepeat:
+		max := re.Max
+		if max < 0 {
+			max = re.Min + maxRepeat
+		}
+		repeat(b, re.Sub[0], rnd, groups, re.Min, max)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			generate(b, sub, rnd, groups)
+		}
+	case syntax.OpAlternate:
+		generate(b, re.Sub[rnd.Intn(len(re.Sub))], rnd, groups)
+	}
+}
+
+func repeat(b *strings.Builder, re *syntax.Regexp, rnd *rand.Rand, groups map[string][]string, min, max int) {
+	// White space is removed from tracking numbers, so don't bother.
+	if isSpace(re) {
+		return
+	}
+
+	n := min + rnd.Intn(max-min+1)
+	for i := 0; i < n; i++ {
+		generate(b, re, rnd, groups)
+	}
+}
+
+// isSpace reports whether re only matches white space.
+func isSpace(re *syntax.Regexp) bool {
+	if re.Op != syntax.OpCharClass {
+		return false
+	}
+	for i := 0; i+1 < len(re.Rune); i += 2 {
+		for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+			if !unicode.IsSpace(r) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// pickRune picks a random rune from a character class, preferring printable
+// ASCII.
+func pickRune(ranges []rune, rnd *rand.Rand) rune {
+	var ascii []rune
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < '!' {
+			lo = '!'
+		}
+		if hi > '~' {
+			hi = '~'
+		}
+		if lo <= hi {
+			ascii = append(ascii, lo, hi)
+		}
+	}
+	if len(ascii) > 0 {
+		ranges = ascii
+	}
+
+	var total int
+	for i := 0; i+1 < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+	if total == 0 {
+		return ' '
+	}
+
+	n := rnd.Intn(total)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			return ranges[i] + rune(n)
+		}
+		n -= size
+	}
+
+	return ranges[0]
+}
@@ -0,0 +1,262 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// hSpace is the PCRE \h horizontal white space class, without brackets.
+const hSpace = `\t\x{20}\x{A0}\x{1680}\x{180E}\x{2000}-\x{200A}\x{202F}\x{205F}\x{3000}`
+
+// quantifier matches a counted repetition such as {2}, {2,} or {2,5}.
+var quantifier = regexp.MustCompile(`^\{[0-9]+(,[0-9]*)?\}`)
+
+// PCREError reports a PCRE construct with no RE2 equivalent.
+type PCREError struct {
+	Expr      string
+	Offset    int
+	Construct string
+}
+
+func (e *PCREError) Error() string {
+	return fmt.Sprintf("unsupported PCRE %s at offset %d in %q", e.Construct, e.Offset, e.Expr)
+}
+
+// Pattern is a PCRE expression translated to RE2. Lookaheads at the start
+// of an anchored expression, such as ^(?!92), become predicates checked at
+// the start of the input in addition to the regex.
+type Pattern struct {
+	Regex   string
+	Require []string // anchored expressions that must match
+	Forbid  []string // anchored expressions that must not match
+}
+
+// TranslatePCRE converts a PCRE expression to RE2 syntax.
+//
+// Named groups (?<name>) and (?'name') become (?P<name>), \h becomes a
+// character class, and leading lookaheads of an anchored expression become
+// predicates. Other lookarounds, atomic groups, possessive quantifiers, back
+// references, recursion and conditionals return a *PCREError. RE2 can't
+// refuse to give back what an atomic group or possessive quantifier
+// consumed, so they would match more than the PCRE expression.
+func TranslatePCRE(expr string) (Pattern, error) {
+	var p Pattern
+
+	pos := 0
+	anchored := strings.HasPrefix(expr, "^")
+	if anchored {
+		pos = 1
+	}
+
+	for strings.HasPrefix(expr[pos:], "(?=") || strings.HasPrefix(expr[pos:], "(?!") {
+		end := closingParen(expr, pos)
+		if end < 0 {
+			return p, fmt.Errorf("missing closing ) at offset %d in %q", pos, expr)
+		}
+		if !anchored {
+			return p, &PCREError{Expr: expr, Offset: pos, Construct: "unanchored lookahead"}
+		}
+
+		body, err := translate(expr, pos+3, end)
+		if err != nil {
+			return p, err
+		}
+		if expr[pos+2] == '=' {
+			p.Require = append(p.Require, "^(?:"+body+")")
+		} else {
+			p.Forbid = append(p.Forbid, "^(?:"+body+")")
+		}
+		pos = end + 1
+	}
+
+	re, err := translate(expr, pos, len(expr))
+	if err != nil {
+		return p, err
+	}
+	if anchored {
+		re = "^" + re
+	}
+	p.Regex = re
+
+	return p, nil
+}
+
+// translate converts expr[start:end] to RE2 syntax.
+func translate(expr string, start, end int) (string, error) {
+	var b strings.Builder
+	unsupported := func(i int, construct string) error {
+		return &PCREError{Expr: expr, Offset: i, Construct: construct}
+	}
+
+	inClass := false
+	lastQuant := false // the previous token was a quantifier
+
+	for i := start; i < end; i++ {
+		c := expr[i]
+		quant := false
+
+		switch {
+		case c == '\\' && i+1 < end:
+			i++
+			switch e := expr[i]; {
+			case e == 'h' && inClass:
+				b.WriteString(hSpace)
+			case e == 'h':
+				b.WriteString("[" + hSpace + "]")
+			case e == 'H' && inClass:
+				return "", unsupported(i-1, `\H in a character class`)
+			case e == 'H':
+				b.WriteString("[^" + hSpace + "]")
+			case e == 'Z' && !inClass:
+				b.WriteString(`(?:\n?\z)`)
+			case e >= '1' && e <= '9' && !inClass:
+				return "", unsupported(i-1, "back reference")
+			case e == 'k' || e == 'g':
+				return "", unsupported(i-1, "back reference")
+			case e == 'G' || e == 'K' || e == 'R' || e == 'X':
+				return "", unsupported(i-1, `\`+string(e))
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+
+		case inClass:
+			if c == '[' && i+1 < end && expr[i+1] == ':' {
+				if j := strings.Index(expr[i:end], ":]"); j > 0 {
+					b.WriteString(expr[i : i+j+2])
+					i += j + 1
+					continue
+				}
+			}
+			if c == ']' {
+				inClass = false
+			}
+			b.WriteByte(c)
+
+		case c == '[':
+			inClass = true
+			b.WriteByte(c)
+			// A ] first in the class, possibly after ^, is literal.
+			if i+1 < end && expr[i+1] == '^' {
+				i++
+				b.WriteByte('^')
+			}
+			if i+1 < end && expr[i+1] == ']' {
+				i++
+				b.WriteString(`\]`)
+			}
+
+		case c == '(' && strings.HasPrefix(expr[i:end], "(?"):
+			rest := expr[i+2 : end]
+			switch {
+			case strings.HasPrefix(rest, "<=") || strings.HasPrefix(rest, "<!"):
+				return "", unsupported(i, "lookbehind")
+			case strings.HasPrefix(rest, "=") || strings.HasPrefix(rest, "!"):
+				return "", unsupported(i, "lookahead")
+			case strings.HasPrefix(rest, "<"):
+				b.WriteString("(?P<")
+				i += 2
+			case strings.HasPrefix(rest, "'"):
+				j := strings.IndexByte(rest[1:], '\'')
+				if j < 0 {
+					return "", unsupported(i, "unterminated group name")
+				}
+				b.WriteString("(?P<" + rest[1:j+1] + ">")
+				i += 2 + j + 1
+			case strings.HasPrefix(rest, ">"):
+				return "", unsupported(i, "atomic group")
+			case strings.HasPrefix(rest, "#"):
+				j := strings.IndexByte(rest, ')')
+				if j < 0 {
+					return "", unsupported(i, "unterminated comment")
+				}
+				i += 2 + j
+			case strings.HasPrefix(rest, "("):
+				return "", unsupported(i, "conditional")
+			case strings.HasPrefix(rest, "|"):
+				return "", unsupported(i, "branch reset")
+			case strings.HasPrefix(rest, "P="):
+				return "", unsupported(i, "back reference")
+			case isRecursion(rest):
+				return "", unsupported(i, "recursion")
+			default:
+				b.WriteString("(?")
+				i++
+			}
+
+		case c == '{' && quantifier.MatchString(expr[i:end]):
+			q := quantifier.FindString(expr[i:end])
+			b.WriteString(q)
+			i += len(q) - 1
+			quant = true
+
+		case c == '*' || c == '+' || c == '?':
+			switch {
+			case lastQuant && c == '+':
+				return "", unsupported(i, "possessive quantifier")
+			case lastQuant && c == '?':
+				b.WriteByte(c) // lazy
+			default:
+				b.WriteByte(c)
+				quant = true
+			}
+
+		default:
+			b.WriteByte(c)
+		}
+
+		lastQuant = quant
+	}
+
+	if inClass {
+		return "", fmt.Errorf("missing closing ] in %q", expr)
+	}
+
+	return b.String(), nil
+}
+
+// isRecursion reports whether a group body following "(?" is a recursion
+// or subroutine call such as (?R), (?1), (?-1), (?&name) or (?P>name).
+func isRecursion(rest string) bool {
+	if strings.HasPrefix(rest, "R") || strings.HasPrefix(rest, "&") || strings.HasPrefix(rest, "P>") {
+		return true
+	}
+	if strings.HasPrefix(rest, "+") || strings.HasPrefix(rest, "-") {
+		rest = rest[1:]
+	}
+
+	return len(rest) > 0 && rest[0] >= '0' && rest[0] <= '9'
+}
+
+// closingParen returns the index of the ) closing the group opened at
+// expr[open], or -1.
+func closingParen(expr string, open int) int {
+	depth := 0
+	inClass := false
+	for i := open; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '\\':
+			i++
+		case inClass:
+			inClass = c != ']'
+		case c == '[':
+			inClass = true
+			if i+1 < len(expr) && expr[i+1] == '^' {
+				i++
+			}
+			if i+1 < len(expr) && expr[i+1] == ']' {
+				i++
+			}
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTranslatePCRE(t *testing.T) {
+	tests := []struct {
+		name      string
+		pcre      string
+		want      Pattern
+		construct string
+	}{
+		{
+			name: "named groups",
+			pcre: `(?<Serial>[0-9]{8})(?'Check'[0-9])`,
+			want: Pattern{Regex: `(?P<Serial>[0-9]{8})(?P<Check>[0-9])`},
+		},
+		{
+			name: "negative lookahead",
+			pcre: `^(?!92).+`,
+			want: Pattern{Regex: `^.+`, Forbid: []string{`^(?:92)`}},
+		},
+		{
+			name: "lookaheads",
+			pcre: `^(?=[0-9])(?!0{4})(?!(1|2)\))[0-9]+`,
+			want: Pattern{Regex: `^[0-9]+`, Require: []string{`^(?:[0-9])`}, Forbid: []string{`^(?:0{4})`, `^(?:(1|2)\))`}},
+		},
+		{
+			name: "lazy",
+			pcre: `[0-9]+?X??Y*?Z{2,3}?`,
+			want: Pattern{Regex: `[0-9]+?X??Y*?Z{2,3}?`},
+		},
+		{
+			name: "horizontal space",
+			pcre: `1Z\h*[\h0-9]+\H`,
+			want: Pattern{Regex: `1Z[` + hSpace + `]*[` + hSpace + `0-9]+[^` + hSpace + `]`},
+		},
+		{
+			name: "literals",
+			pcre: `[]+(?]\(?<x>\)\+{a}(?#note)(?i:a)`,
+			want: Pattern{Regex: `[\]+(?]\(?<x>\)\+{a}(?i:a)`},
+		},
+		{name: "unanchored lookahead", pcre: `(?!92).+`, construct: "unanchored lookahead"},
+		{name: "inner lookahead", pcre: `^A(?!92).+`, construct: "lookahead"},
+		{name: "lookbehind", pcre: `(?<=A)B`, construct: "lookbehind"},
+		{name: "negative lookbehind", pcre: `(?<!A)B`, construct: "lookbehind"},
+		{name: "back reference", pcre: `(A)\1`, construct: "back reference"},
+		{name: "named back reference", pcre: `(?<a>A)\k<a>`, construct: "back reference"},
+		{name: "recursion", pcre: `A(?R)?`, construct: "recursion"},
+		{name: "subroutine", pcre: `(A)(?-1)`, construct: "recursion"},
+		{name: "conditional", pcre: `(A)?(?(1)B|C)`, construct: "conditional"},
+		{name: "keep", pcre: `A\KB`, construct: `\K`},
+		{name: "atomic group", pcre: `(?>AB|A)B`, construct: "atomic group"},
+		{name: "possessive plus", pcre: `[0-9]++X`, construct: "possessive quantifier"},
+		{name: "possessive star", pcre: `Y*+Z`, construct: "possessive quantifier"},
+		{name: "possessive optional", pcre: `X?+Y`, construct: "possessive quantifier"},
+		{name: "possessive count", pcre: `Z{2,3}+`, construct: "possessive quantifier"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TranslatePCRE(tt.pcre)
+
+			var pe *PCREError
+			if tt.construct != "" {
+				if !errors.As(err, &pe) || pe.Construct != tt.construct {
+					t.Fatalf("TranslatePCRE(%q) error %v, expected unsupported %s", tt.pcre, err, tt.construct)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TranslatePCRE(%q) error %v", tt.pcre, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TranslatePCRE(%q) = %#v, expected %#v", tt.pcre, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegexParser(t *testing.T) {
+	tests := []struct {
+		pcre  string
+		in    string
+		match bool
+	}{
+		{`^(?!92).+`, "6129098349792366623", true},
+		{`^(?!92).+`, "926129098349792366623", false},
+		{`^(?=1Z)[0-9A-Z]+`, "1Z5R89390357567127", true},
+		{`^(?=1Z)[0-9A-Z]+`, "5R89390357567127", false},
+		{`(?<A>[0-9]+)X`, "123X", true},
+	}
+
+	for _, tt := range tests {
+		var r RegexParser
+		b, _ := json.Marshal(tt.pcre)
+		if err := json.Unmarshal(b, &r); err != nil {
+			t.Fatalf("RegexParser.UnmarshalJSON(%q) error %v", tt.pcre, err)
+		}
+		if got := r.MatchString(tt.in); got != tt.match {
+			t.Errorf("%q MatchString(%q) = %v, expected %v", tt.pcre, tt.in, got, tt.match)
+		}
+		if got := r.FindStringSubmatch(tt.in) != nil; got != tt.match {
+			t.Errorf("%q FindStringSubmatch(%q) = %v, expected %v", tt.pcre, tt.in, got, tt.match)
+		}
+	}
+
+	var zero RegexParser
+	if zero.MatchString("") || zero.FindStringSubmatch("") != nil {
+		t.Error("zero RegexParser should never match")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"embed"
 	"encoding/json"
 	"fmt"
+	"io"
 	"regexp"
 	"strings"
 
@@ -106,6 +107,15 @@ type Validation struct {
 	} `json:"serial_number_format"`
 }
 
+// Checksums are the check digit algorithm names known to SetValidator.
+var Checksums = []string{
+	"mod7",
+	"mod10",
+	"s10",
+	"sum_product_with_weightings_and_modulo",
+	"mod_37_36",
+}
+
 // SetValidator applies the appropriate validation function for check digits.
 func (val *Validation) SetValidator() {
 	c := val.CheckDigitOpts
@@ -145,8 +155,17 @@ type PrependIf struct {
 }
 
 // RegexParser is a helper type to convert the PCRE regex to compatible Regex.
+// See TranslatePCRE.
 type RegexParser struct {
 	Regex *regexp.Regexp
+
+	// Require and Forbid are translated leading lookaheads, matched at the
+	// start of the input.
+	Require []*regexp.Regexp
+	Forbid  []*regexp.Regexp
+
+	// Source is the original PCRE expression from the courier json file.
+	Source string
 }
 
 func (r *RegexParser) UnmarshalJSON(buf []byte) error {
@@ -164,17 +183,81 @@ func (r *RegexParser) UnmarshalJSON(buf []byte) error {
 	}
 
 	str := strings.Join(out, "")
+	r.Source = str
+
+	p, err := TranslatePCRE(str)
+	if err != nil {
+		return err
+	}
+
+	if r.Regex, err = regexp.Compile(p.Regex); err != nil {
+		return err
+	}
+	for _, expr := range p.Require {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return err
+		}
+		r.Require = append(r.Require, re)
+	}
+	for _, expr := range p.Forbid {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return err
+		}
+		r.Forbid = append(r.Forbid, re)
+	}
+
+	return nil
+}
+
+// predicates reports whether the translated lookaheads hold for s.
+func (r RegexParser) predicates(s string) bool {
+	for _, re := range r.Require {
+		if !re.MatchString(s) {
+			return false
+		}
+	}
+	for _, re := range r.Forbid {
+		if re.MatchString(s) {
+			return false
+		}
+	}
 
-	// Fix PCRE Named Groups for RE2
-	str = strings.ReplaceAll(str, "(?<", "(?P<")
+	return true
+}
 
-	// Fix PrependIf Regex since negated look aheads aren't supported
-	str = strings.ReplaceAll(str, "^(?!", "^(")
+// MatchString reports whether s matches, including any lookaheads. It is
+// false if there is no regex.
+func (r RegexParser) MatchString(s string) bool {
+	return r.Regex != nil && r.predicates(s) && r.Regex.MatchString(s)
+}
 
-	var err error
-	r.Regex, err = regexp.Compile(str)
+// FindStringSubmatch is regexp.Regexp.FindStringSubmatch, returning nil if
+// a lookahead doesn't hold.
+func (r RegexParser) FindStringSubmatch(s string) []string {
+	if r.Regex == nil || !r.predicates(s) {
+		return nil
+	}
 
-	return err
+	return r.Regex.FindStringSubmatch(s)
+}
+
+// String returns the RE2 expression followed by any lookaheads.
+func (r RegexParser) String() string {
+	if r.Regex == nil {
+		return ""
+	}
+
+	s := r.Regex.String()
+	for _, re := range r.Require {
+		s += " [must match " + re.String() + "]"
+	}
+	for _, re := range r.Forbid {
+		s += " [must not match " + re.String() + "]"
+	}
+
+	return s
 }
 
 // loadServices loads the json definitions for all courier services when this
@@ -194,17 +277,90 @@ func loadServices(fs embed.FS) []Service {
 			continue
 		}
 		defer func() { file.Close() }()
-		var courier Courier
-		if err := json.NewDecoder(file).Decode(&courier); err != nil {
+		courier, err := ParseCourier(file)
+		if err != nil {
 			continue
 		}
-		for _, service := range courier.Services {
-			service.CourierCode = courier.CourierCode
-			service.CourierName = courier.Name
-			service.Validation.SetValidator()
-			services = append(services, service)
-		}
+		services = append(services, courier.Services...)
 	}
 
 	return services
 }
+
+// ParseCourier decodes a courier json file. The services are flattened with
+// the courier name and code, and their validators are set.
+func ParseCourier(r io.Reader) (Courier, error) {
+	var courier Courier
+	if err := json.NewDecoder(r).Decode(&courier); err != nil {
+		return courier, err
+	}
+
+	courier.flatten()
+
+	return courier, nil
+}
+
+// ServiceError is a service of a courier json file that couldn't be decoded.
+type ServiceError struct {
+	ID   string
+	Name string
+	Err  error
+}
+
+func (e *ServiceError) Error() string {
+	label := e.ID
+	if label == "" {
+		label = e.Name
+	}
+
+	return label + ": " + e.Err.Error()
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+// ParseCourierServices decodes a courier json file like ParseCourier, except
+// that a service failing to decode, such as one with an unsupported regex,
+// is left out and returned as a ServiceError. The error is for the file
+// itself.
+func ParseCourierServices(r io.Reader) (Courier, []*ServiceError, error) {
+	var raw struct {
+		Name        string            `json:"name"`
+		CourierCode string            `json:"courier_code"`
+		Services    []json.RawMessage `json:"tracking_numbers"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return Courier{}, nil, err
+	}
+
+	courier := Courier{Name: raw.Name, CourierCode: raw.CourierCode}
+	var errs []*ServiceError
+	for _, b := range raw.Services {
+		var service Service
+		if err := json.Unmarshal(b, &service); err != nil {
+			var label struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			}
+			_ = json.Unmarshal(b, &label)
+			errs = append(errs, &ServiceError{ID: label.ID, Name: label.Name, Err: err})
+			continue
+		}
+		courier.Services = append(courier.Services, service)
+	}
+	courier.flatten()
+
+	return courier, errs, nil
+}
+
+// flatten copies the courier name and code into its services and sets their
+// validators.
+func (c *Courier) flatten() {
+	for i := range c.Services {
+		service := &c.Services[i]
+		service.CourierCode = c.CourierCode
+		service.CourierName = c.Name
+		service.Validation.SetValidator()
+	}
+}
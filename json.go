@@ -0,0 +1,56 @@
+package parcel
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion is the version of the Tracking JSON encoding. It changes
+// only for incompatible changes; new optional fields and details keys keep
+// the version.
+const SchemaVersion = 1
+
+var ErrSchemaVersion = errors.New("unsupported tracking schema version")
+
+// JSONSchema is the JSON Schema describing the Tracking JSON encoding.
+//
+//go:embed tracking.schema.json
+var JSONSchema []byte
+
+// tracking has the fields of Tracking without its methods.
+type tracking Tracking
+
+// MarshalJSON encodes the tracking result with its schema version. Details
+// are always encoded as an object.
+func (t Tracking) MarshalJSON() ([]byte, error) {
+	if t.Details == nil {
+		t.Details = map[string]string{}
+	}
+
+	return json.Marshal(struct {
+		SchemaVersion int `json:"schema_version"`
+		tracking
+	}{SchemaVersion, tracking(t)})
+}
+
+// UnmarshalJSON decodes a tracking result. A missing schema version is
+// accepted as the current one; newer versions return ErrSchemaVersion.
+func (t *Tracking) UnmarshalJSON(b []byte) error {
+	v := struct {
+		SchemaVersion int `json:"schema_version"`
+		*tracking
+	}{tracking: (*tracking)(t)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	if v.SchemaVersion > SchemaVersion {
+		return fmt.Errorf("%w: %d", ErrSchemaVersion, v.SchemaVersion)
+	}
+	if t.Details == nil {
+		t.Details = map[string]string{}
+	}
+
+	return nil
+}
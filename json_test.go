@@ -0,0 +1,119 @@
+package parcel_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/internal"
+)
+
+type jsonSchema struct {
+	Required   []string              `json:"required"`
+	Properties map[string]jsonSchema `json:"properties"`
+	Const      any                   `json:"const"`
+}
+
+// Every valid test number must round trip and match the published schema.
+func TestJSON(t *testing.T) {
+	var schema jsonSchema
+	if err := json.Unmarshal(parcel.JSONSchema, &schema); err != nil {
+		t.Fatalf("json.Unmarshal() schema error %v", err)
+	}
+	if schema.Properties["schema_version"].Const != float64(parcel.SchemaVersion) {
+		t.Errorf("schema_version const %v, expected %d", schema.Properties["schema_version"].Const, parcel.SchemaVersion)
+	}
+
+	for _, service := range internal.Services {
+		for _, test := range service.TestNumbers.Valid {
+			got, err := parcel.Track(test)
+			if err != nil {
+				t.Fatalf("parcel.Track() error %v", err)
+			}
+
+			for _, want := range got {
+				b, err := json.Marshal(want)
+				if err != nil {
+					t.Fatalf("json.Marshal() error %v", err)
+				}
+
+				var fields map[string]json.RawMessage
+				if err := json.Unmarshal(b, &fields); err != nil {
+					t.Fatalf("json.Unmarshal() error %v", err)
+				}
+				for _, k := range schema.Required {
+					if _, ok := fields[k]; !ok {
+						t.Errorf("%s: %s missing required field %q", service.ID, test, k)
+					}
+				}
+				for k := range fields {
+					if _, ok := schema.Properties[k]; !ok {
+						t.Errorf("%s: %s field %q not in schema", service.ID, test, k)
+					}
+				}
+				for k := range want.Details {
+					if _, ok := schema.Properties["details"].Properties[k]; !ok && !strings.HasSuffix(k, ":other") {
+						t.Errorf("%s: %s details key %q not in schema", service.ID, test, k)
+					}
+				}
+
+				var rt parcel.Tracking
+				if err := json.Unmarshal(b, &rt); err != nil {
+					t.Fatalf("json.Unmarshal() error %v", err)
+				}
+				if !reflect.DeepEqual(rt, want) {
+					t.Errorf("%s: %s round trip = %+v, expected %+v", service.ID, test, rt, want)
+				}
+			}
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want parcel.Tracking
+		err  error
+	}{
+		{
+			name: "without version",
+			in:   `{"courier":"ups","tracking_number":"1Z5R89390357567127"}`,
+			want: parcel.Tracking{Courier: "ups", TrackingNumber: "1Z5R89390357567127", Details: map[string]string{}},
+		},
+		{
+			name: "current version",
+			in:   `{"schema_version":1,"courier":"ups","details":{"ServiceType":"03"}}`,
+			want: parcel.Tracking{Courier: "ups", Details: map[string]string{"ServiceType": "03"}},
+		},
+		{
+			name: "newer version",
+			in:   `{"schema_version":2,"courier":"ups"}`,
+			err:  parcel.ErrSchemaVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got parcel.Tracking
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("json.Unmarshal() error %v, expected %v", err, tt.err)
+			}
+			if tt.err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("json.Unmarshal() = %+v, expected %+v", got, tt.want)
+			}
+		})
+	}
+
+	b, err := json.Marshal(parcel.Tracking{Courier: "ups"})
+	if err != nil {
+		t.Fatalf("json.Marshal() error %v", err)
+	}
+	if !strings.Contains(string(b), `"details":{}`) {
+		t.Errorf("json.Marshal() = %s, expected empty details object", b)
+	}
+}
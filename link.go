@@ -0,0 +1,244 @@
+package parcel
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// linkPatterns are carrier tracking URLs recognised by Find besides the
+	// service TrackingURL and the URL templates. A "*" path segment matches
+	// any segment, such as a locale.
+	linkPatterns = []URLTemplate{
+		{Courier: "amazon", Template: "https://track.amazon.com/tracking/{TrackingNumber}"},
+		{Courier: "canada_post", Template: "https://www.canadapost-postescanada.ca/track-reperage/*#/search?searchFor={TrackingNumber}"},
+		{Courier: "canada_post", Template: "https://www.canadapost.ca/trackweb/*#/search?searchFor={TrackingNumber}"},
+		{Courier: "dhl", Template: "https://www.dhl.com/*/home/tracking.html?tracking-id={TrackingNumber}"},
+		{Courier: "dhl", Template: "https://www.dhl.com/*/express/tracking.html?AWB={TrackingNumber}"},
+		{Courier: "fedex", Template: "https://www.fedex.com/fedextrack/?trknbr={TrackingNumber}"},
+		{Courier: "fedex", Template: "https://www.fedex.com/fedextrack/?tracknumbers={TrackingNumber}"},
+		{Courier: "fedex", Template: "https://www.fedex.com/apps/fedextrack/?trackingnumber={TrackingNumber}"},
+		{Courier: "ups", Template: "https://www.ups.com/track?tracknum={TrackingNumber}"},
+		{Courier: "ups", Template: "https://www.ups.com/mobile/track?trackingNumber={TrackingNumber}"},
+		{Courier: "ups", Template: "https://wwwapps.ups.com/tracking/tracking.cgi?tracknum={TrackingNumber}"},
+		{Courier: "usps", Template: "https://tools.usps.com/go/TrackConfirmAction_input?qtc_tLabels1={TrackingNumber}"},
+		{Courier: "usps", Template: "https://tools.usps.com/go/TrackConfirmAction.action?tLabels={TrackingNumber}"},
+	}
+
+	links = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"'()\[\]{}]+`)
+)
+
+// RegisterURLPattern adds a carrier tracking URL that Find extracts numbers
+// from. Unlike RegisterURL it doesn't change Tracking.URL.
+func RegisterURLPattern(t URLTemplate) {
+	urlMu.Lock()
+	defer urlMu.Unlock()
+
+	linkPatterns = append(linkPatterns, t)
+}
+
+// linkPattern is a tracking URL template taken apart for reverse matching.
+type linkPattern struct {
+	courier, service string
+
+	host, path string
+	fragment   *linkPattern // pattern of a "#/path?query" fragment
+
+	// Where the number is: a query parameter, or else a path segment.
+	key     string
+	segment int
+}
+
+// marker stands in for the tracking number while parsing a template.
+const marker = "PARCELTRACKINGNUMBER"
+
+// newLinkPattern parses a template using {TrackingNumber} or %s. It reports
+// false for templates without the full number.
+func newLinkPattern(courier, service, tmpl string) (*linkPattern, bool) {
+	tmpl = strings.Replace(tmpl, "%s", "{TrackingNumber}", 1)
+	if !strings.Contains(tmpl, "{TrackingNumber}") {
+		return nil, false
+	}
+	u, err := url.Parse(strings.Replace(tmpl, "{TrackingNumber}", marker, 1))
+	if err != nil || placeholder.MatchString(u.String()) {
+		return nil, false
+	}
+
+	p, ok := newPathPattern(u)
+	if !ok {
+		return nil, false
+	}
+	p.courier, p.service, p.host = courier, service, trimHost(u.Host)
+
+	return p, true
+}
+
+// newPathPattern locates the marker in the path, query or fragment of u.
+func newPathPattern(u *url.URL) (*linkPattern, bool) {
+	p := &linkPattern{path: trimPath(u.Path), segment: -1}
+
+	for k, vs := range u.Query() {
+		if len(vs) == 1 && vs[0] == marker {
+			p.key = k
+			return p, true
+		}
+	}
+	for i, s := range strings.Split(p.path, "/") {
+		if s == marker {
+			p.segment = i
+			return p, true
+		}
+	}
+	if u.Fragment != "" {
+		f, err := url.Parse(u.Fragment)
+		if err != nil {
+			return nil, false
+		}
+		if p.fragment, _ = newPathPattern(f); p.fragment != nil {
+			return p, true
+		}
+	}
+
+	return nil, false
+}
+
+// match returns the numbers in u if it is a URL of the pattern.
+func (p *linkPattern) match(u *url.URL) []string {
+	if p.host != "" && trimHost(u.Host) != p.host {
+		return nil
+	}
+
+	path := strings.Split(trimPath(u.Path), "/")
+	want := strings.Split(p.path, "/")
+	if len(path) != len(want) {
+		return nil
+	}
+	for i := range want {
+		if i != p.segment && want[i] != "*" && !strings.EqualFold(want[i], path[i]) {
+			return nil
+		}
+	}
+
+	switch {
+	case p.fragment != nil:
+		f, err := url.Parse(u.Fragment)
+		if err != nil {
+			return nil
+		}
+		return p.fragment.match(f)
+	case p.key != "":
+		for k, vs := range u.Query() {
+			if strings.EqualFold(k, p.key) && len(vs) > 0 {
+				return splitNumbers(vs[0])
+			}
+		}
+		return nil
+	default:
+		return splitNumbers(path[p.segment])
+	}
+}
+
+// splitNumbers splits a URL value listing several numbers.
+func splitNumbers(v string) []string {
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	})
+}
+
+func trimHost(h string) string {
+	return strings.TrimPrefix(strings.ToLower(h), "www.")
+}
+
+func trimPath(p string) string {
+	return strings.Trim(p, "/")
+}
+
+// linkPatterns returns the patterns of the service TrackingURLs, the URL
+// templates and the extra carrier URLs.
+func (r *Registry) linkPatterns() []*linkPattern {
+	urlMu.RLock()
+	templates := append(append([]URLTemplate{}, urlTemplates...), linkPatterns...)
+	urlMu.RUnlock()
+
+	// Service TrackingURLs are shared by the services of a courier, so
+	// they only identify the courier.
+	for _, s := range r.services {
+		templates = append(templates, URLTemplate{Courier: s.CourierCode, Template: s.TrackingURL})
+	}
+
+	seen := make(map[URLTemplate]bool)
+	out := make([]*linkPattern, 0, len(templates))
+	for _, t := range templates {
+		t = URLTemplate{Courier: t.Courier, Service: t.Service, Template: t.Template}
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if p, ok := newLinkPattern(t.Courier, t.Service, t.Template); ok {
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
+
+// findLinks adds the numbers found in carrier tracking URLs in the input.
+// Results of the URL courier are preferred over other matches.
+func (r *Registry) findLinks(in string, f *filter, out map[string][]Tracking) {
+	found := links.FindAllString(in, -1)
+	if len(found) == 0 {
+		return
+	}
+
+	patterns := r.linkPatterns()
+	for _, link := range found {
+		link = strings.TrimRight(link, ".,;:!?")
+		if !strings.Contains(link, "://") {
+			link = "https://" + link
+		}
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+
+		for _, p := range patterns {
+			nums := p.match(u)
+			for _, num := range nums {
+				res, err := r.track(num, f)
+				if err != nil || len(res) == 0 {
+					continue
+				}
+				res = p.prefer(res)
+				for i := range res {
+					res[i].addEvidence(fmt.Sprintf("tracking URL %s", u.Host))
+				}
+				out[num] = res
+			}
+			if len(nums) > 0 {
+				break
+			}
+		}
+	}
+}
+
+// prefer keeps the results of the pattern's service or courier, if any.
+func (p *linkPattern) prefer(res []Tracking) []Tracking {
+	for _, keep := range []func(Tracking) bool{
+		func(t Tracking) bool { return p.service != "" && t.ServiceID == p.service },
+		func(t Tracking) bool { return t.Courier == p.courier },
+	} {
+		var out []Tracking
+		for _, t := range res {
+			if keep(t) {
+				out = append(out, t)
+			}
+		}
+		if len(out) > 0 {
+			return out
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,94 @@
+package parcel_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+func TestFindLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[string][]string
+	}{
+		{
+			name: "ups",
+			in:   "Track it at https://www.ups.com/track?loc=en_US&tracknum=1Z5R89390357567127&requester=ST/.",
+			want: map[string][]string{"1Z5R89390357567127": {"ups"}},
+		},
+		{
+			name: "usps several",
+			in:   "<a href=\"https://tools.usps.com/go/TrackConfirmAction?tRef=fullpage&tLabels=9400111201080805483016%2C9361289878700317633795\">track</a>",
+			want: map[string][]string{"9400111201080805483016": {"usps_91"}, "9361289878700317633795": {"usps_91"}},
+		},
+		{
+			name: "service url",
+			in:   "see http://www.dhl.com/en/express/tracking.html?brand=DHL&AWB=986578788855",
+			want: map[string][]string{"986578788855": {"dhl_express"}},
+		},
+		{
+			name: "fedex without scheme",
+			in:   "www.fedex.com/fedextrack/?trknbr=986578788855&trkqual=x",
+			want: map[string][]string{"986578788855": {"fedex_12"}},
+		},
+		{
+			name: "fragment",
+			in:   "https://www.canadapost-postescanada.ca/track-reperage/fr#/search?searchFor=7035114477138472",
+			want: map[string][]string{"7035114477138472": {"canada_post"}},
+		},
+		{
+			name: "path",
+			in:   "https://track.amazon.com/tracking/TBA123456789012",
+			want: map[string][]string{"TBA123456789012": {"amazon_international", "amazon_logistics"}},
+		},
+		{
+			name: "unknown host",
+			in:   "https://example.com/track?tracknum=986578788855x",
+			want: map[string][]string{},
+		},
+		{
+			name: "invalid number",
+			in:   "https://www.ups.com/track?tracknum=1Z5R89390357567128",
+			want: map[string][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := parcel.Find(tt.in)
+			if err != nil {
+				t.Fatalf("parcel.Find() error %v", err)
+			}
+			got := make(map[string][]string)
+			for num, r := range res {
+				got[num] = keys(r)
+				for _, v := range r {
+					if len(v.Evidence) == 0 {
+						t.Errorf("parcel.Find() %s has no evidence", num)
+					}
+				}
+			}
+			for _, v := range tt.want {
+				sort.Strings(v)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parcel.Find() = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterURLPattern(t *testing.T) {
+	parcel.RegisterURLPattern(parcel.URLTemplate{Courier: "dhl", Template: "https://dhl.example/t/{TrackingNumber}/details"})
+
+	res, err := parcel.Find("https://dhl.example/t/986578788855/details")
+	if err != nil {
+		t.Fatalf("parcel.Find() error %v", err)
+	}
+	if got := keys(res["986578788855"]); !reflect.DeepEqual(got, []string{"dhl_express"}) {
+		t.Errorf("parcel.Find() = %v, expected [dhl_express]", got)
+	}
+}
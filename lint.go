@@ -0,0 +1,322 @@
+package parcel
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"unicode"
+
+	"dev.freespoke.com/go-package-tracking/internal"
+)
+
+// LintIssue is a problem found in a courier json file.
+type LintIssue struct {
+	File    string `json:"file"`
+	Service string `json:"service,omitempty"` // service ID, or name if it has none
+	Message string `json:"message"`
+}
+
+func (i LintIssue) String() string {
+	if i.Service == "" {
+		return i.File + ": " + i.Message
+	}
+
+	return i.File + ": " + i.Service + ": " + i.Message
+}
+
+// Lint checks the courier json files in the root of fsys, such as
+// os.DirFS(dir), the same way they are loaded by this package. Unlike
+// loading, which skips broken files, it reports every problem found.
+// The error is only for failing to read the directory.
+func Lint(fsys fs.FS) ([]LintIssue, error) {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	issues := make([]LintIssue, 0)
+	ids := map[string]string{}
+
+	for _, name := range names {
+		f, err := fsys.Open(name)
+		if err != nil {
+			issues = append(issues, LintIssue{File: name, Message: err.Error()})
+			continue
+		}
+		courier, errs, err := internal.ParseCourierServices(f)
+		f.Close()
+		if err != nil {
+			issues = append(issues, LintIssue{File: name, Message: err.Error()})
+			continue
+		}
+
+		if courier.CourierCode == "" {
+			issues = append(issues, LintIssue{File: name, Message: "missing courier_code"})
+		}
+		// A service that can't be decoded is reported and the rest are
+		// still checked.
+		for _, e := range errs {
+			label := e.ID
+			if label == "" {
+				label = e.Name
+			}
+			issues = append(issues, LintIssue{File: name, Service: label, Message: e.Err.Error()})
+		}
+		if len(courier.Services) == 0 && len(errs) == 0 {
+			issues = append(issues, LintIssue{File: name, Message: "no tracking_numbers"})
+		}
+
+		for _, service := range courier.Services {
+			label := service.ID
+			if label == "" {
+				label = service.Name
+			}
+			if service.ID != "" {
+				if other, ok := ids[service.ID]; ok {
+					issues = append(issues, LintIssue{File: name, Service: label, Message: "id also used in " + other})
+				}
+				ids[service.ID] = name
+			}
+
+			for _, msg := range lintService(service) {
+				issues = append(issues, LintIssue{File: name, Service: label, Message: msg})
+			}
+		}
+	}
+
+	return issues, nil
+}
+
+// LintDir checks the courier json files in a directory. See Lint.
+func LintDir(fsys fs.FS, dir string) ([]LintIssue, error) {
+	sub, err := fs.Sub(fsys, path.Clean(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	return Lint(sub)
+}
+
+// lintService returns the problems with a single service.
+func lintService(service internal.Service) []string {
+	var out []string
+	fail := func(format string, a ...any) {
+		out = append(out, fmt.Sprintf(format, a...))
+	}
+
+	if service.Name == "" {
+		fail("missing name")
+	}
+
+	re := service.Regex.Regex
+	if re == nil {
+		fail("missing regex")
+		return out
+	}
+
+	groups := map[string]bool{}
+	for _, g := range re.SubexpNames() {
+		groups[g] = true
+	}
+
+	opts := service.Validation.CheckDigitOpts
+	if opts.Name != "" {
+		known := false
+		for _, c := range internal.Checksums {
+			known = known || c == opts.Name
+		}
+		if !known {
+			fail("unknown checksum %q", opts.Name)
+		}
+		for _, g := range []string{"SerialNumber", "CheckDigit"} {
+			if !groups[g] {
+				fail("checksum %s needs a %s regex group", opts.Name, g)
+			}
+		}
+	}
+
+	switch opts.Name {
+	case "mod10":
+		if opts.EvensMultiplier == 0 && opts.OddsMultiplier == 0 {
+			fail("mod10 needs evens_multiplier or odds_multiplier")
+		}
+	case "s10":
+		if n := len(opts.Weightings); n != 0 && n != 8 {
+			fail("s10 needs 8 weightings, has %d", n)
+		}
+	case "sum_product_with_weightings_and_modulo":
+		if len(opts.Weightings) == 0 {
+			fail("%s needs weightings", opts.Name)
+		} else if w := groupWidth(re, "SerialNumber"); w > 0 && !serialFits(service, w, len(opts.Weightings)) {
+			fail("%s needs %d weightings, has %d", opts.Name, w, len(opts.Weightings))
+		}
+		if opts.Modulo1 == 0 || opts.Modulo2 == 0 {
+			fail("%s needs modulo1 and modulo2", opts.Name)
+		}
+	}
+
+	prepend := service.Validation.SerialNumberFormat.PrependIf
+	switch {
+	case prepend.Regex.Regex == nil && prepend.Content != "":
+		fail("prepend_if needs matches_regex")
+	case prepend.Regex.Regex != nil && prepend.Content == "":
+		fail("prepend_if needs content")
+	case prepend.Regex.Regex != nil && !groups["SerialNumber"]:
+		fail("prepend_if needs a SerialNumber regex group")
+	}
+
+	for _, key := range service.Validation.Additional.Exists {
+		found := false
+		for _, a := range service.Additional {
+			found = found || a.Name == key
+		}
+		if !found {
+			fail("exists %q has no additional entry", key)
+		}
+	}
+	for _, a := range service.Additional {
+		if !groups[a.RegexGroupName] {
+			fail("additional %q uses missing regex group %q", a.Name, a.RegexGroupName)
+		}
+	}
+
+	// The test numbers can't be checked without a validator.
+	if service.Validation.Validator == nil || len(out) > 0 {
+		return out
+	}
+
+	if len(service.TestNumbers.Valid) == 0 {
+		fail("no valid test numbers")
+	}
+	for _, n := range service.TestNumbers.Valid {
+		var ex Explanation
+		if _, ok := evaluate(service, normalize(n), &ex); !ok {
+			fail("valid test number %q doesn't match: %s", n, ex.Reason())
+		}
+	}
+	for _, n := range service.TestNumbers.Invalid {
+		if _, ok := evaluate(service, normalize(n), nil); ok {
+			fail("invalid test number %q matches", n)
+		}
+	}
+
+	return out
+}
+
+// serialFits reports whether n weightings fit a serial number of width
+// characters, before or after the prepend_if content is added.
+func serialFits(service internal.Service, width, n int) bool {
+	prepend := service.Validation.SerialNumberFormat.PrependIf
+
+	return n == width || prepend.Regex.Regex != nil && n == width+len(prepend.Content)
+}
+
+// groupWidth returns the number of characters other than white space the
+// named regex group always matches, or -1 if it varies or there is no such
+// group.
+func groupWidth(re *regexp.Regexp, name string) int {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return -1
+	}
+
+	var find func(r *syntax.Regexp) *syntax.Regexp
+	find = func(r *syntax.Regexp) *syntax.Regexp {
+		if r.Op == syntax.OpCapture && r.Name == name {
+			return r
+		}
+		for _, sub := range r.Sub {
+			if g := find(sub); g != nil {
+				return g
+			}
+		}
+		return nil
+	}
+	g := find(parsed)
+	if g == nil {
+		return -1
+	}
+
+	return fixedWidth(g)
+}
+
+// fixedWidth returns the number of characters other than white space r
+// always matches, or -1 if it varies.
+func fixedWidth(r *syntax.Regexp) int {
+	switch r.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText,
+		syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return 0
+	case syntax.OpLiteral:
+		n := 0
+		for _, c := range r.Rune {
+			if !unicode.IsSpace(c) {
+				n++
+			}
+		}
+		return n
+	case syntax.OpCharClass:
+		if spaceClass(r.Rune) {
+			return 0
+		}
+		return 1
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return 1
+	case syntax.OpCapture:
+		return fixedWidth(r.Sub[0])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest:
+		if fixedWidth(r.Sub[0]) == 0 {
+			return 0
+		}
+		return -1
+	case syntax.OpRepeat:
+		w := fixedWidth(r.Sub[0])
+		if w <= 0 {
+			return w
+		}
+		if r.Min != r.Max {
+			return -1
+		}
+		return w * r.Min
+	case syntax.OpConcat:
+		n := 0
+		for _, sub := range r.Sub {
+			w := fixedWidth(sub)
+			if w < 0 {
+				return -1
+			}
+			n += w
+		}
+		return n
+	case syntax.OpAlternate:
+		n := fixedWidth(r.Sub[0])
+		for _, sub := range r.Sub[1:] {
+			if fixedWidth(sub) != n {
+				return -1
+			}
+		}
+		return n
+	}
+
+	return -1
+}
+
+// spaceClass reports whether a character class only matches white space.
+func spaceClass(ranges []rune) bool {
+	for i := 0; i+1 < len(ranges); i += 2 {
+		if ranges[i+1]-ranges[i] > 0xFF {
+			return false
+		}
+		for c := ranges[i]; c <= ranges[i+1]; c++ {
+			if !unicode.IsSpace(c) {
+				return false
+			}
+		}
+	}
+
+	return len(ranges) > 0
+}
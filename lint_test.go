@@ -0,0 +1,141 @@
+package parcel_test
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	tracking "github.com/jkeen/tracking_number_data"
+)
+
+func TestLintEmbedded(t *testing.T) {
+	issues, err := parcel.LintDir(tracking.Couriers, "couriers")
+	if err != nil {
+		t.Fatalf("parcel.LintDir() error %v", err)
+	}
+	for _, i := range issues {
+		t.Errorf("parcel.LintDir() %s", i)
+	}
+}
+
+const lintCourier = `{
+  "name": "Example",
+  "courier_code": "example",
+  "tracking_numbers": [
+    {
+      "id": "ok",
+      "name": "OK",
+      "regex": "\\s*(?<SerialNumber>([0-9]\\s*){6})(?<CheckDigit>[0-9]\\s*)",
+      "validation": {"checksum": {"name": "mod7"}},
+      "test_numbers": {"valid": ["1234564"], "invalid": ["1234565"]}
+    },
+    {
+      "id": "ok",
+      "name": "Duplicate",
+      "regex": "X(?<SerialNumber>[0-9]{6})(?<CheckDigit>[0-9])",
+      "validation": {"checksum": {"name": "mod7"}},
+      "test_numbers": {"valid": ["X1234564"]}
+    },
+    {
+      "id": "unknown_checksum",
+      "name": "Unknown",
+      "regex": "(?<SerialNumber>[0-9]{6})(?<CheckDigit>[0-9])",
+      "validation": {"checksum": {"name": "mod11"}}
+    },
+    {
+      "id": "missing_group",
+      "name": "Missing Group",
+      "regex": "(?<SerialNumber>[0-9]{7})",
+      "validation": {"checksum": {"name": "mod10", "evens_multiplier": 3, "odds_multiplier": 1}}
+    },
+    {
+      "id": "bad_weightings",
+      "name": "Bad Weightings",
+      "regex": "(?<SerialNumber>[0-9]{7})(?<CheckDigit>[0-9])",
+      "validation": {"checksum": {"name": "sum_product_with_weightings_and_modulo", "weightings": [3, 1]}}
+    },
+    {
+      "id": "prepend_content",
+      "name": "Prepend Content",
+      "regex": "(?<SerialNumber>[0-9]{6})",
+      "validation": {"serial_number_format": {"prepend_if": {"content": "91"}}}
+    },
+    {
+      "id": "prepend_regex",
+      "name": "Prepend Regex",
+      "regex": "(?<SerialNumber>[0-9]{6})",
+      "validation": {"serial_number_format": {"prepend_if": {"matches_regex": "^[0-9]"}}}
+    },
+    {
+      "id": "short_s10",
+      "name": "Short S10",
+      "regex": "(?<SerialNumber>[0-9]{7})(?<CheckDigit>[0-9])",
+      "validation": {"checksum": {"name": "s10", "weightings": [8, 6, 4, 2, 3, 5, 9]}}
+    },
+    {
+      "id": "exists",
+      "name": "Exists",
+      "regex": "(?<ServiceType>[A-Z]{2})(?<SerialNumber>[0-9]{6})",
+      "validation": {"additional": {"exists": ["Courier"]}},
+      "additional": [{"name": "Service Type", "regex_group_name": "Type", "lookup": []}]
+    },
+    {
+      "id": "tests",
+      "name": "Tests",
+      "regex": "(?<SerialNumber>[0-9]{6})(?<CheckDigit>[0-9])",
+      "validation": {"checksum": {"name": "mod7"}},
+      "test_numbers": {"valid": ["1234565"], "invalid": ["1234564"]}
+    }
+  ]
+}`
+
+func TestLint(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bad.json":     {Data: []byte(`{"name": `)},
+		"example.json": {Data: []byte(lintCourier)},
+		"lookahead.json": {Data: []byte(`{"courier_code": "x", "tracking_numbers": [
+			{"id": "x", "name": "X", "regex": "(?<SerialNumber>[0-9]+)(?=X)"},
+			{"id": "y", "name": "Y", "regex": "(?<SerialNumber>[0-9]+)",
+				"validation": {"checksum": {"name": "mod99"}}}
+		]}`)},
+		"notes.txt": {Data: []byte("not checked")},
+	}
+
+	issues, err := parcel.Lint(fsys)
+	if err != nil {
+		t.Fatalf("parcel.Lint() error %v", err)
+	}
+
+	got := make([]string, 0, len(issues))
+	for _, i := range issues {
+		got = append(got, i.String())
+	}
+	all := strings.Join(got, "\n")
+
+	want := []string{
+		"bad.json: unexpected EOF",
+		"example.json: ok: id also used in example.json",
+		`example.json: unknown_checksum: unknown checksum "mod11"`,
+		"example.json: missing_group: checksum mod10 needs a CheckDigit regex group",
+		"example.json: bad_weightings: sum_product_with_weightings_and_modulo needs modulo1 and modulo2",
+		"example.json: bad_weightings: sum_product_with_weightings_and_modulo needs 7 weightings, has 2",
+		"example.json: prepend_content: prepend_if needs matches_regex",
+		"example.json: prepend_regex: prepend_if needs content",
+		"example.json: short_s10: s10 needs 8 weightings, has 7",
+		`example.json: exists: exists "Courier" has no additional entry`,
+		`example.json: exists: additional "Service Type" uses missing regex group "Type"`,
+		`example.json: tests: valid test number "1234565" doesn't match: check digit "5" fails mod7, expected "4"`,
+		`example.json: tests: invalid test number "1234564" matches`,
+		"lookahead.json: x: unsupported PCRE lookahead at offset 23",
+		`lookahead.json: y: unknown checksum "mod99"`,
+	}
+	for _, w := range want {
+		if !strings.Contains(all, w) {
+			t.Errorf("parcel.Lint() missing %q in\n%s", w, all)
+		}
+	}
+	if strings.Contains(all, "example.json: ok: valid") || strings.Contains(all, "notes.txt") {
+		t.Errorf("parcel.Lint() unexpected issue in\n%s", all)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strings"
 	"sync"
+	"unicode"
 	"unicode/utf8"
 
 	"dev.freespoke.com/go-package-tracking/internal"
@@ -17,110 +18,176 @@ var (
 )
 
 // Tracking contains results extracted from a valid tracking number.
+//
+// Its JSON encoding is a stable contract described by tracking.schema.json
+// and versioned by SchemaVersion.
 type Tracking struct {
 	// Always returned
-	Courier        string
-	Service        string
-	TrackingNumber string
-	SerialNumber   string
+	Courier        string `json:"courier"`
+	Service        string `json:"service"`
+	TrackingNumber string `json:"tracking_number"`
+	SerialNumber   string `json:"serial_number"`
 
 	// Always populated if used
-	CheckDigit  string
-	TrackingURL string
+	CheckDigit  string `json:"check_digit,omitempty"`
+	ServiceID   string `json:"service_id,omitempty"`
+	TrackingURL string `json:"tracking_url,omitempty"`
 
 	// Extra details that may be encoded into the tracking number.
-	Details map[string]string
+	// Keys are regex group names such as ServiceType, or values looked up
+	// from them such as ServiceName; see tracking.schema.json.
+	Details map[string]string `json:"details"`
+
+	// Evidence lists the courier keywords found near the number by Find
+	// with WithContext.
+	Evidence []string `json:"evidence,omitempty"`
 }
 
 // Track identifies valid package tracking codes.
 // If a valid code is identified, it returns encoded tracking information.
-// There may be multiple matches.
-func Track(in string) ([]Tracking, error) {
+// There may be multiple matches. Options restrict the services checked.
+func Track(in string, opts ...Option) ([]Tracking, error) {
+	return defaultRegistry.Track(in, opts...)
+}
+
+// Track identifies valid package tracking codes among the registry
+// services. See Track.
+func (r *Registry) Track(in string, opts ...Option) ([]Tracking, error) {
+	return r.track(in, newFilter(opts))
+}
+
+func (r *Registry) track(in string, f *filter) ([]Tracking, error) {
 	// Exit early if not a simple string
 	if len(in) != utf8.RuneCountInString(in) {
 		return nil, ErrBadString
 	}
 
-	// Exit early if no tracking services available to check.
-	if len(internal.Services) == 0 {
+	// Exit early if the registry has no tracking services to check.
+	if len(r.services) == 0 {
 		return nil, ErrNoServices
 	}
 
-	in = strings.ReplaceAll(strings.ToUpper(in), " ", "")
+	in = normalize(in)
 	res := make([]Tracking, 0)
 
-	for _, service := range internal.Services {
-		// initialize a single, empty result
-		tracker := Tracking{
-			Details: map[string]string{},
+	for _, service := range r.servicesFor(f) {
+		if tracker, ok := evaluate(service, in, nil); ok && r.allowsResult(f, tracker) {
+			res = append(res, tracker)
 		}
+	}
 
-		// Identify potential matches
-		if service.Regex.Regex.MatchString(in) {
-			matches := service.Regex.Regex.FindStringSubmatch(in)
-			for i, val := range matches {
-				if key := service.Regex.Regex.SubexpNames()[i]; key != "" {
-					val = strings.TrimSpace(val)
-					if val != "" {
-						tracker.Details[key] = val
-					}
-				}
-			}
+	return applyPrecedence(res), nil
+}
 
-			if v, ok := tracker.Details["SerialNumber"]; ok {
-				prepend := service.Validation.SerialNumberFormat.PrependIf
-				if prepend.Regex.Regex != nil && !prepend.Regex.Regex.MatchString(v) {
-					v = prepend.Content + v
-				}
-				tracker.SerialNumber = v
-				delete(tracker.Details, "SerialNumber")
-			}
+// normalize prepares a tracking number for matching.
+func normalize(in string) string {
+	return strings.ReplaceAll(strings.ToUpper(in), " ", "")
+}
+
+// evaluate checks a normalized tracking number against a single service.
+// If ex isn't nil, it records each step of the evaluation.
+func evaluate(service internal.Service, in string, ex *Explanation) (Tracking, bool) {
+	// initialize a single, empty result
+	tracker := Tracking{
+		Details: map[string]string{},
+	}
+
+	// Identify potential matches
+	matches := service.Regex.FindStringSubmatch(in)
+	if matches == nil {
+		ex.step("regex does not match")
+		return tracker, false
+	}
+	ex.step("regex matches %q", matches[0])
 
-			if v, ok := tracker.Details["CheckDigit"]; ok {
-				tracker.CheckDigit = v
-				delete(tracker.Details, "CheckDigit")
+	for i, val := range matches {
+		if key := service.Regex.Regex.SubexpNames()[i]; key != "" {
+			val = strings.TrimSpace(val)
+			if val != "" {
+				tracker.Details[key] = val
 			}
+		}
+	}
 
-			// Confirm match
-			if service.Validation.Validator.Validate(tracker.SerialNumber, tracker.CheckDigit) {
-				match := true
-				// If additional validations exist, check them
-				for _, matchKey := range service.Validation.Additional.Exists {
-					if ok := service.ValidateAdditionalExists(matchKey, tracker.Details); !ok {
-						match = false
-						break
-					}
-				}
-				if !match {
-					continue
-				}
+	if v, ok := tracker.Details["SerialNumber"]; ok {
+		prepend := service.Validation.SerialNumberFormat.PrependIf
+		if prepend.Regex.MatchString(v) {
+			v = prepend.Content + v
+			ex.step("serial number prepended with %q", prepend.Content)
+		}
+		tracker.SerialNumber = v
+		delete(tracker.Details, "SerialNumber")
+	}
 
-				tracker.TrackingNumber = in
-				tracker.Courier = service.CourierCode
-				tracker.Service = service.Name
-				if service.TrackingURL != "" {
-					tracker.TrackingURL = fmt.Sprintf(service.TrackingURL, in)
-				}
+	if v, ok := tracker.Details["CheckDigit"]; ok {
+		tracker.CheckDigit = v
+		delete(tracker.Details, "CheckDigit")
+	}
+	if ex != nil {
+		ex.SerialNumber = tracker.SerialNumber
+		ex.CheckDigit = tracker.CheckDigit
+	}
 
-				// Populate additional details
-				tracker.populate(service.Additional)
+	// Confirm match
+	checksum := service.Validation.CheckDigitOpts.Name
+	if checksum == "" {
+		checksum = "none"
+	}
+	if !service.Validation.Validator.Validate(tracker.SerialNumber, tracker.CheckDigit) {
+		want, _ := service.Validation.Validator.Generate(tracker.SerialNumber)
+		ex.step("check digit %q fails %s, expected %q", tracker.CheckDigit, checksum, want)
+		return tracker, false
+	}
+	ex.step("check digit passes %s", checksum)
 
-				res = append(res, tracker)
-			}
+	// If additional validations exist, check them
+	for _, matchKey := range service.Validation.Additional.Exists {
+		if ok := service.ValidateAdditionalExists(matchKey, tracker.Details); !ok {
+			ex.step("no %s lookup for the extracted value", matchKey)
+			return tracker, false
 		}
+		ex.step("%s lookup exists", matchKey)
+	}
+
+	tracker.TrackingNumber = in
+	tracker.Courier = service.CourierCode
+	tracker.Service = service.Name
+	tracker.ServiceID = service.ID
+	if service.TrackingURL != "" {
+		tracker.TrackingURL = fmt.Sprintf(service.TrackingURL, in)
 	}
 
-	return res, nil
+	// Populate additional details
+	tracker.populate(service.Additional)
+
+	return tracker, true
+}
+
+// TrimTerm strips the punctuation around a term found by Find, such as a
+// trailing comma or surrounding parentheses, leaving the number as written.
+func TrimTerm(term string) string {
+	return strings.TrimFunc(term, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
 }
 
 // Find extracts detected tracking numbers from a string based on word boundaries.
 // It returns a list of tracking results with the corresponding tracking number.
-func Find(in string) (map[string][]Tracking, error) {
-	// Exit early if no tracking services available to check.
-	if len(internal.Services) == 0 {
+// Numbers in carrier tracking URLs are found too, preferring the results of
+// the URL courier. Options restrict the services checked.
+func Find(in string, opts ...Option) (map[string][]Tracking, error) {
+	return defaultRegistry.Find(in, opts...)
+}
+
+// Find extracts detected tracking numbers among the registry services. See
+// Find.
+func (r *Registry) Find(in string, opts ...Option) (map[string][]Tracking, error) {
+	// Exit early if the registry has no tracking services to check.
+	if len(r.services) == 0 {
 		return nil, ErrNoServices
 	}
 
+	f := newFilter(opts)
 	out := make(map[string][]Tracking)
 	terms := strings.Fields(in)
 
@@ -131,7 +198,11 @@ func Find(in string) (map[string][]Tracking, error) {
 	for _, v := range terms {
 		go func(v string, wg *sync.WaitGroup, mu *sync.Mutex) {
 			defer wg.Done()
-			track, err := Track(v)
+			// Links are left to findLinks.
+			if links.MatchString(v) {
+				return
+			}
+			track, err := r.track(v, f)
 			if err == nil && len(track) != 0 {
 				mu.Lock()
 				defer mu.Unlock()
@@ -142,11 +213,17 @@ func Find(in string) (map[string][]Tracking, error) {
 
 	wg.Wait()
 
+	r.findLinks(in, f, out)
+
+	if window, mode := r.context(f); window > 0 {
+		disambiguate(terms, out, window, mode)
+	}
+
 	// If no results yet, try handling the case with a single tracking number
 	// containing white space.
-	if len(out) == 0 {
+	if len(out) == 0 && !links.MatchString(in) {
 		in = regexp.MustCompile(`\s`).ReplaceAllString(in, "")
-		track, err := Track(in)
+		track, err := r.track(in, f)
 		if err == nil && len(track) != 0 {
 			out[in] = track
 		}
@@ -166,10 +243,8 @@ func (t *Tracking) populate(details []internal.Additional) {
 					if v == lookup.Matches {
 						found = true
 					}
-					if r := lookup.MatchesRegex.Regex; r != nil {
-						if r.MatchString(v) {
-							found = true
-						}
+					if lookup.MatchesRegex.MatchString(v) {
+						found = true
 					}
 					if found {
 						switch k {
@@ -56,3 +56,19 @@ func TestAllServices(t *testing.T) {
 		}
 	}
 }
+
+func TestTrimTerm(t *testing.T) {
+	tests := map[string]string{
+		"1Z5R89390357567127":   "1Z5R89390357567127",
+		"1Z5R89390357567127,":  "1Z5R89390357567127",
+		"(1Z5R89390357567127)": "1Z5R89390357567127",
+		"#RB123456785GB.":      "RB123456785GB",
+		"--":                   "",
+	}
+
+	for in, want := range tests {
+		if got := parcel.TrimTerm(in); got != want {
+			t.Errorf("parcel.TrimTerm(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package parcel
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Precedence resolves a collision between two services. When a number
+// matches both, Track only returns the Winner. Services are identified by
+// service key, see Tracking.ServiceKey.
+type Precedence struct {
+	Winner string `json:"winner"`
+	Loser  string `json:"loser"`
+}
+
+var ErrPrecedenceCycle = errors.New("precedence rule closes a cycle")
+
+var (
+	precMu     sync.RWMutex
+	precedence []Precedence
+)
+
+// RegisterPrecedence adds precedence rules. A rule replaces an earlier rule
+// for the same pair of services in either order. A rule that would let a
+// service beat itself through other rules, such as A over B and B over C
+// followed by C over A, returns ErrPrecedenceCycle and no rule is added.
+func RegisterPrecedence(rules ...Precedence) error {
+	precMu.Lock()
+	defer precMu.Unlock()
+
+	next := append([]Precedence(nil), precedence...)
+	for _, r := range rules {
+		kept := next[:0]
+		for _, p := range next {
+			same := p.Winner == r.Winner && p.Loser == r.Loser
+			reversed := p.Winner == r.Loser && p.Loser == r.Winner
+			if !same && !reversed {
+				kept = append(kept, p)
+			}
+		}
+		if r.Winner == r.Loser || beats(kept, r.Loser, r.Winner) {
+			return fmt.Errorf("%w: %s over %s", ErrPrecedenceCycle, r.Winner, r.Loser)
+		}
+		next = append(kept, r)
+	}
+	precedence = next
+
+	return nil
+}
+
+// beats reports whether the rules let winner beat loser, directly or
+// through other services.
+func beats(rules []Precedence, winner, loser string) bool {
+	seen := map[string]bool{winner: true}
+	queue := []string{winner}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+		for _, p := range rules {
+			if p.Winner != k || seen[p.Loser] {
+				continue
+			}
+			if p.Loser == loser {
+				return true
+			}
+			seen[p.Loser] = true
+			queue = append(queue, p.Loser)
+		}
+	}
+
+	return false
+}
+
+// beaten returns the service keys among the matched keys that lose to
+// another matched key, mapped to the winner. A key only loses to a winner
+// that doesn't lose itself, so at least one key always remains.
+func beaten(keys []string) map[string]string {
+	precMu.RLock()
+	defer precMu.RUnlock()
+
+	present := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		present[k] = true
+	}
+
+	winners := map[string][]string{}
+	for _, p := range precedence {
+		if present[p.Winner] && present[p.Loser] {
+			winners[p.Loser] = append(winners[p.Loser], p.Winner)
+		}
+	}
+
+	out := map[string]string{}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[string]int{}
+	var survives func(k string) bool
+	survives = func(k string) bool {
+		switch state[k] {
+		case visiting:
+			// RegisterPrecedence rejects cycles, but never let one
+			// remove every key.
+			return true
+		case done:
+			_, lost := out[k]
+			return !lost
+		}
+
+		state[k] = visiting
+		for _, w := range winners[k] {
+			if survives(w) {
+				out[k] = w
+				break
+			}
+		}
+		state[k] = done
+
+		_, lost := out[k]
+		return !lost
+	}
+	for _, k := range keys {
+		survives(k)
+	}
+
+	return out
+}
+
+// applyPrecedence removes the results beaten by another result.
+func applyPrecedence(res []Tracking) []Tracking {
+	if len(res) < 2 {
+		return res
+	}
+
+	keys := make([]string, len(res))
+	for i, t := range res {
+		keys[i] = t.ServiceKey()
+	}
+	lost := beaten(keys)
+	if len(lost) == 0 {
+		return res
+	}
+
+	out := res[:0]
+	for i, t := range res {
+		if _, ok := lost[keys[i]]; !ok {
+			out = append(out, t)
+		}
+	}
+
+	return out
+}
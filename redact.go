@@ -0,0 +1,203 @@
+package parcel
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// maskChar replaces hidden characters of a masked tracking number.
+	maskChar = "*"
+
+	// maskPrefixMax is the longest courier prefix kept by Masked.
+	maskPrefixMax = 4
+
+	// maskSuffix is the number of trailing characters kept by Masked.
+	maskSuffix = 4
+
+	// tokenLength is the number of hex characters kept from the HMAC.
+	tokenLength = 16
+)
+
+// Masked returns the tracking number with everything except the courier
+// prefix and the last four characters replaced by asterisks.
+// The courier prefix is the part of the number preceding the serial number,
+// such as "1Z" for UPS or "RB" for S10 numbers.
+func (t Tracking) Masked() string {
+	num := t.number()
+	if len(num) <= maskSuffix {
+		return strings.Repeat(maskChar, len(num))
+	}
+
+	prefix := t.prefix()
+	if len(prefix)+maskSuffix >= len(num) {
+		prefix = ""
+	}
+
+	hidden := len(num) - len(prefix) - maskSuffix
+	return prefix + strings.Repeat(maskChar, hidden) + num[len(num)-maskSuffix:]
+}
+
+// Token returns a stable, keyed token for the tracking number.
+// The same courier, number and key always produce the same token so redacted
+// records remain joinable without exposing the number itself.
+func (t Tracking) Token(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(t.Courier + ":" + t.number()))
+	sum := hex.EncodeToString(mac.Sum(nil))
+
+	return t.Courier + ":" + sum[:tokenLength]
+}
+
+// prefix returns the leading part of the tracking number before the serial
+// number, limited to a short courier prefix.
+func (t Tracking) prefix() string {
+	if t.SerialNumber == "" {
+		return ""
+	}
+
+	num := t.number()
+	i := strings.Index(num, t.SerialNumber)
+	if i <= 0 || i > maskPrefixMax {
+		return ""
+	}
+
+	return num[:i]
+}
+
+// number returns the normalized tracking number without the punctuation of
+// the term it was found in, such as a trailing comma or surrounding
+// parentheses.
+func (t Tracking) number() string {
+	return TrimTerm(normalize(t.TrackingNumber))
+}
+
+// Redact finds tracking numbers in a string and replaces each one with its
+// masked form. See Tracking.Masked.
+func Redact(in string) (string, error) {
+	return redact(in, Tracking.Masked)
+}
+
+// RedactToken finds tracking numbers in a string and replaces each one with
+// a keyed token. See Tracking.Token.
+func RedactToken(in string, key []byte) (string, error) {
+	return redact(in, func(t Tracking) string {
+		return t.Token(key)
+	})
+}
+
+// redact replaces every tracking number found in the string with the result
+// of the replace function.
+func redact(in string, replace func(Tracking) string) (string, error) {
+	found, err := Find(in)
+	if err != nil {
+		return "", err
+	}
+
+	// Replace longer terms first so a term contained in another can't
+	// clobber it.
+	terms := make([]string, 0, len(found))
+	for term := range found {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i]) != len(terms[j]) {
+			return len(terms[i]) > len(terms[j])
+		}
+		return terms[i] < terms[j]
+	})
+
+	pairs := make([]string, 0, len(terms)*2)
+	for _, term := range terms {
+		tracks := found[term]
+		if len(tracks) == 0 {
+			continue
+		}
+
+		// Find falls back to a single number containing white space, which
+		// can't be replaced as a term. Replace only the words holding it.
+		if !strings.Contains(in, term) {
+			start, end, t, ok := spaced(in, tracks)
+			if !ok {
+				return in, nil
+			}
+			return in[:start] + replace(t) + in[end:], nil
+		}
+
+		// Keep the punctuation around the number.
+		num := TrimTerm(term)
+		pairs = append(pairs, term, strings.Replace(term, num, replace(tracks[0]), 1))
+	}
+
+	return strings.NewReplacer(pairs...).Replace(in), nil
+}
+
+// wordPattern matches a run of non-space characters.
+var wordPattern = regexp.MustCompile(`\S+`)
+
+// spacedSlack is the number of characters beyond the serial number a run of
+// words may hold, leaving room for a courier prefix and check digit.
+const spacedSlack = 8
+
+// spaced finds the shortest run of words in the string that tracks to the
+// same service and serial number as one of the tracks, returning its span.
+func spaced(in string, tracks []Tracking) (start, end int, track Tracking, ok bool) {
+	words := wordPattern.FindAllStringIndex(in, -1)
+	best := -1
+
+	for i := range words {
+		var joined string
+		for j := i; j < len(words); j++ {
+			joined += strings.ToUpper(in[words[j][0]:words[j][1]])
+			if best >= 0 && j-i >= best {
+				break
+			}
+
+			t, found := spacedMatch(joined, tracks)
+			if found {
+				start, end, track, ok = words[i][0], words[j][1], t, true
+				best = j - i
+				break
+			}
+			if tooLong(joined, tracks) {
+				break
+			}
+		}
+	}
+
+	return start, end, track, ok
+}
+
+// spacedMatch tracks the joined words and returns the result sharing a
+// service and serial number with one of the tracks.
+func spacedMatch(joined string, tracks []Tracking) (Tracking, bool) {
+	res, err := Track(joined)
+	if err != nil {
+		return Tracking{}, false
+	}
+	for _, r := range res {
+		for _, t := range tracks {
+			if r.ServiceKey() == t.ServiceKey() && r.SerialNumber == t.SerialNumber {
+				return r, true
+			}
+		}
+	}
+
+	return Tracking{}, false
+}
+
+// tooLong reports whether the joined words are too long to hold only one of
+// the tracks.
+func tooLong(joined string, tracks []Tracking) bool {
+	for _, t := range tracks {
+		if len(joined) <= len(t.SerialNumber)+spacedSlack {
+			return false
+		}
+	}
+
+	return true
+}
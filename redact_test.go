@@ -0,0 +1,121 @@
+package parcel_test
+
+import (
+	"strings"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+func TestMasked(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "ups", in: "1Z5R89390357567127", want: "1Z************7127"},
+		{name: "s10", in: "RB123456785GB", want: "RB*******85GB"},
+		{name: "fedex", in: "986578788855", want: "********8855"},
+		{name: "comma", in: "1Z5R89390357567127,", want: "1Z************7127"},
+		{name: "parentheses", in: "(1Z5R89390357567127)", want: "1Z************7127"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parcel.Track(tt.in)
+			if err != nil {
+				t.Fatalf("parcel.Track() error %v", err)
+			}
+			if len(got) == 0 {
+				t.Fatal("parcel.Track() expected a result. Got none.")
+			}
+			for _, v := range got {
+				if m := v.Masked(); m != tt.want {
+					t.Errorf("Masked() %s = %s, want %s", v.Service, m, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	in := "track 1Z5R89390357567127 and RB123456785GB today"
+	want := "track 1Z************7127 and RB*******85GB today"
+
+	got, err := parcel.Redact(in)
+	if err != nil {
+		t.Fatalf("parcel.Redact() error %v", err)
+	}
+	if got != want {
+		t.Errorf("parcel.Redact() = %q, want %q", got, want)
+	}
+
+	got, err = parcel.Redact("1Z5R 8939 0357 5671 27")
+	if err != nil {
+		t.Fatalf("parcel.Redact() error %v", err)
+	}
+	if got != "1Z************7127" {
+		t.Errorf("parcel.Redact() with spaces = %q", got)
+	}
+
+	got, err = parcel.Redact("a 7790 1797 2697 b")
+	if err != nil {
+		t.Fatalf("parcel.Redact() error %v", err)
+	}
+	if got != "a ********2697 b" {
+		t.Errorf("parcel.Redact() with surrounding words = %q", got)
+	}
+
+	got, err = parcel.Redact("ship (1Z5R89390357567127), RB123456785GB.")
+	if err != nil {
+		t.Fatalf("parcel.Redact() error %v", err)
+	}
+	if got != "ship (1Z************7127), RB*******85GB." {
+		t.Errorf("parcel.Redact() with punctuation = %q", got)
+	}
+}
+
+func TestRedactToken(t *testing.T) {
+	in := "track 1Z5R89390357567127 today"
+	key := []byte("secret")
+
+	a, err := parcel.RedactToken(in, key)
+	if err != nil {
+		t.Fatalf("parcel.RedactToken() error %v", err)
+	}
+	b, _ := parcel.RedactToken(in, key)
+	if a != b {
+		t.Errorf("parcel.RedactToken() not stable: %q != %q", a, b)
+	}
+	if strings.Contains(a, "1Z5R89390357567127") {
+		t.Errorf("parcel.RedactToken() leaked tracking number: %q", a)
+	}
+	if !strings.HasPrefix(a, "track ups:") {
+		t.Errorf("parcel.RedactToken() = %q, want courier token", a)
+	}
+
+	c, _ := parcel.RedactToken(in, []byte("other"))
+	if a == c {
+		t.Error("parcel.RedactToken() token should depend on key")
+	}
+}
+
+func TestTokenPunctuation(t *testing.T) {
+	key := []byte("secret")
+	want := ""
+	for _, in := range []string{"1Z5R89390357567127", "1Z5R89390357567127,", "(1Z5R89390357567127)"} {
+		got, err := parcel.Track(in)
+		if err != nil {
+			t.Fatalf("parcel.Track() error %v", err)
+		}
+		if len(got) == 0 {
+			t.Fatal("parcel.Track() expected a result. Got none.")
+		}
+		if want == "" {
+			want = got[0].Token(key)
+		}
+		if tok := got[0].Token(key); tok != want {
+			t.Errorf("Token() %q = %s, want %s", in, tok, want)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package parcel
+
+import (
+	"strings"
+
+	"dev.freespoke.com/go-package-tracking/internal"
+)
+
+// courierCountries lists the countries a courier delivers in. Couriers not
+// listed deliver internationally and pass every country filter.
+var courierCountries = map[string][]string{
+	"canada_post": {"CA"},
+	"lasership":   {"US"},
+	"ontrac":      {"US"},
+	"usps":        {"US"},
+}
+
+// serviceCountries overrides courierCountries for a service, by service key.
+var serviceCountries = map[string][]string{
+	"fedex_smartpost": {"US"},
+}
+
+// Option restricts the services checked by Track, Find, Explain and a
+// Registry, or configures how Find picks results. Filters of the same kind
+// add to each other; different kinds must all pass.
+type Option func(*filter)
+
+// WithCouriers only checks the services of the given courier codes, such
+// as "canada_post" or "ups".
+func WithCouriers(codes ...string) Option {
+	return func(f *filter) { f.couriers = addSet(f.couriers, codes) }
+}
+
+// WithoutCouriers skips the services of the given courier codes.
+func WithoutCouriers(codes ...string) Option {
+	return func(f *filter) { f.denyCouriers = addSet(f.denyCouriers, codes) }
+}
+
+// WithServices only checks the given services, by service key. See
+// Tracking.ServiceKey.
+func WithServices(keys ...string) Option {
+	return func(f *filter) { f.services = addSet(f.services, keys) }
+}
+
+// WithoutServices skips the given services, by service key.
+func WithoutServices(keys ...string) Option {
+	return func(f *filter) { f.denyServices = addSet(f.denyServices, keys) }
+}
+
+// WithCountries only checks couriers delivering in the given ISO 3166
+// country codes, such as "CA". International couriers always pass. Results
+// with a CountryCode detail, such as S10 numbers, must also be issued by
+// one of the countries.
+func WithCountries(codes ...string) Option {
+	upper := make([]string, len(codes))
+	for i, c := range codes {
+		upper[i] = strings.ToUpper(c)
+	}
+
+	return func(f *filter) { f.countries = addSet(f.countries, upper) }
+}
+
+// filter holds the options. Nil allow sets allow everything.
+type filter struct {
+	couriers     map[string]bool
+	services     map[string]bool
+	countries    map[string]bool
+	denyCouriers map[string]bool
+	denyServices map[string]bool
+
+	// Keyword disambiguation, see WithContext.
+	context     int
+	contextMode ContextMode
+
+	// Attributes scanned by FindHTML, see WithAttributes.
+	attributes []string
+}
+
+func addSet(set map[string]bool, keys []string) map[string]bool {
+	if set == nil {
+		set = make(map[string]bool, len(keys))
+	}
+	for _, k := range keys {
+		set[k] = true
+	}
+
+	return set
+}
+
+func newFilter(opts []Option) *filter {
+	if len(opts) == 0 {
+		return nil
+	}
+
+	f := &filter{}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	return f
+}
+
+// allows reports whether a service is checked. A nil filter allows all.
+func (f *filter) allows(s internal.Service) bool {
+	if f == nil {
+		return true
+	}
+
+	key := serviceKey(s.CourierCode, s.ID, s.Name)
+	switch {
+	case f.couriers != nil && !f.couriers[s.CourierCode]:
+		return false
+	case f.services != nil && !f.services[key]:
+		return false
+	case f.denyCouriers[s.CourierCode] || f.denyServices[key]:
+		return false
+	}
+
+	if f.countries != nil {
+		countries, ok := serviceCountries[key]
+		if !ok {
+			countries, ok = courierCountries[s.CourierCode]
+		}
+		if ok {
+			for _, c := range countries {
+				if f.countries[c] {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	return true
+}
+
+// allowsResult applies the checks that need the extracted values.
+func (f *filter) allowsResult(t Tracking) bool {
+	if f == nil || f.countries == nil {
+		return true
+	}
+	if c, ok := t.Details["CountryCode"]; ok {
+		return f.countries[c]
+	}
+
+	return true
+}
+
+// Registry is a filtered set of services. The package level functions use a
+// registry of every loaded service.
+type Registry struct {
+	services []internal.Service
+	filter   *filter
+}
+
+var defaultRegistry = NewRegistry()
+
+// NewRegistry returns a registry of the loaded services passing the
+// options. Filtering happens once here rather than on every call.
+func NewRegistry(opts ...Option) *Registry {
+	f := newFilter(opts)
+
+	services := make([]internal.Service, 0, len(internal.Services))
+	for _, s := range internal.Services {
+		if f.allows(s) {
+			services = append(services, s)
+		}
+	}
+
+	return &Registry{services: services, filter: f}
+}
+
+// context returns the keyword window and mode of the call options, falling
+// back to the registry options.
+func (r *Registry) context(f *filter) (int, ContextMode) {
+	if f != nil && f.context > 0 {
+		return f.context, f.contextMode
+	}
+	if r.filter != nil {
+		return r.filter.context, r.filter.contextMode
+	}
+
+	return 0, ContextBoost
+}
+
+// servicesFor returns the registry services passing the call options.
+func (r *Registry) servicesFor(f *filter) []internal.Service {
+	if f == nil {
+		return r.services
+	}
+
+	out := make([]internal.Service, 0, len(r.services))
+	for _, s := range r.services {
+		if f.allows(s) {
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+// allowsResult applies the registry and call filters to a result.
+func (r *Registry) allowsResult(f *filter, t Tracking) bool {
+	return r.filter.allowsResult(t) && f.allowsResult(t)
+}
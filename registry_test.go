@@ -0,0 +1,119 @@
+package parcel_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+func keys(res []parcel.Tracking) []string {
+	out := make([]string, 0, len(res))
+	for _, t := range res {
+		out = append(out, t.ServiceKey())
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+func TestTrackOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		opts []parcel.Option
+		want []string
+	}{
+		{"none", "986578788855", nil, []string{"dhl_express", "fedex_12"}},
+		{"couriers", "986578788855", []parcel.Option{parcel.WithCouriers("canada_post", "purolator", "ups", "fedex")}, []string{"fedex_12"}},
+		{"couriers added", "986578788855", []parcel.Option{parcel.WithCouriers("fedex"), parcel.WithCouriers("dhl")}, []string{"dhl_express", "fedex_12"}},
+		{"without couriers", "986578788855", []parcel.Option{parcel.WithoutCouriers("dhl")}, []string{"fedex_12"}},
+		{"services", "986578788855", []parcel.Option{parcel.WithServices("dhl_express")}, []string{"dhl_express"}},
+		{"without services", "986578788855", []parcel.Option{parcel.WithoutServices("dhl_express", "fedex_12")}, []string{}},
+		{"allow and deny", "986578788855", []parcel.Option{parcel.WithCouriers("dhl", "fedex"), parcel.WithoutServices("fedex_12")}, []string{"dhl_express"}},
+		{"country keeps international", "986578788855", []parcel.Option{parcel.WithCountries("ca")}, []string{"dhl_express", "fedex_12"}},
+		{"country drops domestic", "9400111201080805483016", []parcel.Option{parcel.WithCountries("CA")}, []string{}},
+		{"country keeps domestic", "9400111201080805483016", []parcel.Option{parcel.WithCountries("US")}, []string{"fedex_smartpost", "usps_91"}},
+		{"country of s10", "RB123456785GB", []parcel.Option{parcel.WithCountries("CA")}, []string{}},
+		{"country of s10 matches", "RB123456785GB", []parcel.Option{parcel.WithCountries("CA", "GB")}, []string{"s10"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := parcel.Track(tt.in, tt.opts...)
+			if err != nil {
+				t.Fatalf("parcel.Track() error %v", err)
+			}
+			if got := keys(res); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parcel.Track() = %v, expected %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := parcel.NewRegistry(parcel.WithCountries("CA"), parcel.WithCouriers("canada_post", "ups", "fedex", "dhl"))
+
+	res, err := r.Track("986578788855")
+	if err != nil {
+		t.Fatalf("Registry.Track() error %v", err)
+	}
+	if got := keys(res); !reflect.DeepEqual(got, []string{"dhl_express", "fedex_12"}) {
+		t.Errorf("Registry.Track() = %v", got)
+	}
+
+	// Call options narrow the registry further.
+	res, err = r.Track("986578788855", parcel.WithoutCouriers("dhl"))
+	if err != nil {
+		t.Fatalf("Registry.Track() error %v", err)
+	}
+	if got := keys(res); !reflect.DeepEqual(got, []string{"fedex_12"}) {
+		t.Errorf("Registry.Track() with options = %v", got)
+	}
+
+	found, err := r.Find("ship 1Z5R89390357567127 and 9400111201080805483016 or RB123456785GB")
+	if err != nil {
+		t.Fatalf("Registry.Find() error %v", err)
+	}
+	if len(found) != 1 || found["1Z5R89390357567127"] == nil {
+		t.Errorf("Registry.Find() = %v, expected only the UPS number", found)
+	}
+
+	ex, err := r.Explain("1Z5R89390357567127")
+	if err != nil {
+		t.Fatalf("Registry.Explain() error %v", err)
+	}
+	for _, e := range ex {
+		switch e.Courier {
+		case "canada_post", "ups", "fedex", "dhl":
+		default:
+			t.Errorf("Registry.Explain() evaluated %s", e.Courier)
+		}
+	}
+
+	ex, err = parcel.Explain("RB123456785GB", parcel.WithCountries("CA"))
+	if err != nil {
+		t.Fatalf("parcel.Explain() error %v", err)
+	}
+	for _, e := range ex {
+		if e.ServiceID == "s10" && (e.Match || e.Reason() != "country GB is filtered out") {
+			t.Errorf("parcel.Explain() s10 = %+v", e)
+		}
+	}
+}
+
+func TestEmptyRegistry(t *testing.T) {
+	r := parcel.NewRegistry(parcel.WithCouriers("nobody"))
+
+	if _, err := r.Track("1Z5R89390357567127"); !errors.Is(err, parcel.ErrNoServices) {
+		t.Errorf("Registry.Track() error = %v, expected %v", err, parcel.ErrNoServices)
+	}
+	if _, err := r.Find("ship 1Z5R89390357567127"); !errors.Is(err, parcel.ErrNoServices) {
+		t.Errorf("Registry.Find() error = %v, expected %v", err, parcel.ErrNoServices)
+	}
+	if _, err := r.Explain("1Z5R89390357567127"); !errors.Is(err, parcel.ErrNoServices) {
+		t.Errorf("Registry.Explain() error = %v, expected %v", err, parcel.ErrNoServices)
+	}
+}
@@ -0,0 +1,224 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: parcel.proto
+
+package parcelpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	ParcelService_Track_FullMethodName      = "/parcel.v1.ParcelService/Track"
+	ParcelService_Find_FullMethodName       = "/parcel.v1.ParcelService/Find"
+	ParcelService_FindStream_FullMethodName = "/parcel.v1.ParcelService/FindStream"
+)
+
+// ParcelServiceClient is the client API for ParcelService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ParcelServiceClient interface {
+	// Track identifies a single tracking number.
+	Track(ctx context.Context, in *TrackRequest, opts ...grpc.CallOption) (*TrackResponse, error)
+	// Find extracts tracking numbers from text.
+	Find(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (*FindResponse, error)
+	// FindStream extracts tracking numbers from text sent in chunks, like
+	// Find. Each distinct term is returned as soon as it is found.
+	FindStream(ctx context.Context, opts ...grpc.CallOption) (ParcelService_FindStreamClient, error)
+}
+
+type parcelServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewParcelServiceClient(cc grpc.ClientConnInterface) ParcelServiceClient {
+	return &parcelServiceClient{cc}
+}
+
+func (c *parcelServiceClient) Track(ctx context.Context, in *TrackRequest, opts ...grpc.CallOption) (*TrackResponse, error) {
+	out := new(TrackResponse)
+	err := c.cc.Invoke(ctx, ParcelService_Track_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parcelServiceClient) Find(ctx context.Context, in *FindRequest, opts ...grpc.CallOption) (*FindResponse, error) {
+	out := new(FindResponse)
+	err := c.cc.Invoke(ctx, ParcelService_Find_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *parcelServiceClient) FindStream(ctx context.Context, opts ...grpc.CallOption) (ParcelService_FindStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ParcelService_ServiceDesc.Streams[0], ParcelService_FindStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &parcelServiceFindStreamClient{stream}
+	return x, nil
+}
+
+type ParcelService_FindStreamClient interface {
+	Send(*FindStreamRequest) error
+	Recv() (*FindResult, error)
+	grpc.ClientStream
+}
+
+type parcelServiceFindStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *parcelServiceFindStreamClient) Send(m *FindStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *parcelServiceFindStreamClient) Recv() (*FindResult, error) {
+	m := new(FindResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParcelServiceServer is the server API for ParcelService service.
+// All implementations must embed UnimplementedParcelServiceServer
+// for forward compatibility
+type ParcelServiceServer interface {
+	// Track identifies a single tracking number.
+	Track(context.Context, *TrackRequest) (*TrackResponse, error)
+	// Find extracts tracking numbers from text.
+	Find(context.Context, *FindRequest) (*FindResponse, error)
+	// FindStream extracts tracking numbers from text sent in chunks, like
+	// Find. Each distinct term is returned as soon as it is found.
+	FindStream(ParcelService_FindStreamServer) error
+	mustEmbedUnimplementedParcelServiceServer()
+}
+
+// UnimplementedParcelServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedParcelServiceServer struct {
+}
+
+func (UnimplementedParcelServiceServer) Track(context.Context, *TrackRequest) (*TrackResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Track not implemented")
+}
+func (UnimplementedParcelServiceServer) Find(context.Context, *FindRequest) (*FindResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Find not implemented")
+}
+func (UnimplementedParcelServiceServer) FindStream(ParcelService_FindStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method FindStream not implemented")
+}
+func (UnimplementedParcelServiceServer) mustEmbedUnimplementedParcelServiceServer() {}
+
+// UnsafeParcelServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ParcelServiceServer will
+// result in compilation errors.
+type UnsafeParcelServiceServer interface {
+	mustEmbedUnimplementedParcelServiceServer()
+}
+
+func RegisterParcelServiceServer(s grpc.ServiceRegistrar, srv ParcelServiceServer) {
+	s.RegisterService(&ParcelService_ServiceDesc, srv)
+}
+
+func _ParcelService_Track_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).Track(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_Track_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).Track(ctx, req.(*TrackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParcelService_Find_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FindRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ParcelServiceServer).Find(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ParcelService_Find_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ParcelServiceServer).Find(ctx, req.(*FindRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ParcelService_FindStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ParcelServiceServer).FindStream(&parcelServiceFindStreamServer{stream})
+}
+
+type ParcelService_FindStreamServer interface {
+	Send(*FindResult) error
+	Recv() (*FindStreamRequest, error)
+	grpc.ServerStream
+}
+
+type parcelServiceFindStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *parcelServiceFindStreamServer) Send(m *FindResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *parcelServiceFindStreamServer) Recv() (*FindStreamRequest, error) {
+	m := new(FindStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ParcelService_ServiceDesc is the grpc.ServiceDesc for ParcelService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ParcelService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "parcel.v1.ParcelService",
+	HandlerType: (*ParcelServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Track",
+			Handler:    _ParcelService_Track_Handler,
+		},
+		{
+			MethodName: "Find",
+			Handler:    _ParcelService_Find_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "FindStream",
+			Handler:       _ParcelService_FindStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "parcel.proto",
+}
@@ -0,0 +1,217 @@
+// Package rpc serves tracking number identification over gRPC.
+//
+// The service is defined in parcel.proto; the generated code is in package
+// parcelpb.
+package rpc
+
+//go:generate buf generate
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/internal"
+	"dev.freespoke.com/go-package-tracking/rpc/parcelpb"
+)
+
+// DefaultMaxTerm is the longest run of text without white space FindStream
+// buffers before checking it anyway.
+const DefaultMaxTerm = 1 << 12
+
+// Server implements parcelpb.ParcelServiceServer.
+type Server struct {
+	parcelpb.UnimplementedParcelServiceServer
+
+	// MaxTerm overrides DefaultMaxTerm if positive.
+	MaxTerm int
+}
+
+// NewServer returns a server. Register it with
+// parcelpb.RegisterParcelServiceServer.
+func NewServer() *Server {
+	return &Server{}
+}
+
+func (s *Server) Track(ctx context.Context, req *parcelpb.TrackRequest) (*parcelpb.TrackResponse, error) {
+	res, err := parcel.Track(req.GetNumber())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &parcelpb.TrackResponse{Matches: NewTrackings(res)}, nil
+}
+
+func (s *Server) Find(ctx context.Context, req *parcelpb.FindRequest) (*parcelpb.FindResponse, error) {
+	found, err := parcel.Find(req.GetText())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	res := &parcelpb.FindResponse{Results: make([]*parcelpb.FindResult, 0, len(found))}
+	for term, matches := range found {
+		res.Results = append(res.Results, &parcelpb.FindResult{Term: term, Matches: NewTrackings(matches)})
+	}
+	sort.Slice(res.Results, func(i, j int) bool {
+		return res.Results[i].Term < res.Results[j].Term
+	})
+
+	return res, nil
+}
+
+// FindStream runs the text through Find once the white space after the
+// last term of a chunk arrives, so numbers split across chunks are still
+// found, along with numbers in tracking URLs. The last words of the text
+// already checked are passed again so courier keywords before a number are
+// seen. Unlike Find it doesn't retry the whole text as a single number
+// containing white space.
+func (s *Server) FindStream(stream parcelpb.ParcelService_FindStreamServer) error {
+	maxTerm := s.MaxTerm
+	if maxTerm <= 0 {
+		maxTerm = DefaultMaxTerm
+	}
+
+	seen := map[string]bool{}
+	var carry string
+	var before []string
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			_, err := s.send(stream, seen, before, carry)
+			return err
+		} else if err != nil {
+			return err
+		}
+
+		text := carry + req.GetText()
+		carry = ""
+
+		// Hold back a trailing partial term for the next chunk.
+		if r, _ := utf8.DecodeLastRuneInString(text); text != "" && !unicode.IsSpace(r) {
+			i := strings.LastIndexFunc(text, unicode.IsSpace)
+			if len(text)-(i+1) <= maxTerm {
+				carry = text[i+1:]
+				text = text[:i+1]
+			}
+		}
+
+		if before, err = s.send(stream, seen, before, text); err != nil {
+			return err
+		}
+	}
+}
+
+// streamContext is the number of words of earlier text FindStream passes
+// to Find with each chunk.
+const streamContext = 8
+
+// send finds the numbers in the text and sends the ones not already seen.
+// before holds the last words already checked; send returns them updated.
+func (s *Server) send(stream parcelpb.ParcelService_FindStreamServer, seen map[string]bool, before []string, text string) ([]string, error) {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return before, nil
+	}
+	all := append(append([]string(nil), before...), words...)
+
+	found, err := parcel.Find(strings.Join(all, " "))
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	// Find retries text without matches as a single number; a stream only
+	// holds that number if it had no white space, so it is a term itself.
+	joined := strings.Join(all, "")
+	terms := make([]string, 0, len(found))
+	for term := range found {
+		if len(all) > 1 && term == joined {
+			continue
+		}
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	for _, term := range terms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		if err := stream.Send(&parcelpb.FindResult{Term: term, Matches: NewTrackings(found[term])}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(all) > streamContext {
+		all = all[len(all)-streamContext:]
+	}
+
+	return all, nil
+}
+
+// NewTracking converts a parcel.Tracking to its protobuf form, adding the
+// partners of its service.
+func NewTracking(t parcel.Tracking) *parcelpb.Tracking {
+	return &parcelpb.Tracking{
+		Courier:        t.Courier,
+		Service:        t.Service,
+		ServiceId:      t.ServiceID,
+		TrackingNumber: t.TrackingNumber,
+		SerialNumber:   t.SerialNumber,
+		CheckDigit:     t.CheckDigit,
+		TrackingUrl:    t.TrackingURL,
+		Details:        t.Details,
+		Partners:       partners(t.ServiceID),
+		Evidence:       t.Evidence,
+	}
+}
+
+// NewTrackings converts a list of results.
+func NewTrackings(res []parcel.Tracking) []*parcelpb.Tracking {
+	out := make([]*parcelpb.Tracking, 0, len(res))
+	for _, t := range res {
+		out = append(out, NewTracking(t))
+	}
+
+	return out
+}
+
+// partners returns the partners declared by a service.
+func partners(serviceID string) []*parcelpb.Partner {
+	if serviceID == "" {
+		return nil
+	}
+
+	var out []*parcelpb.Partner
+	for _, s := range internal.Services {
+		if s.ID != serviceID {
+			continue
+		}
+		for _, p := range s.Partners {
+			out = append(out, &parcelpb.Partner{Id: p.PartnerID, Type: p.PartnerType, Description: p.Description})
+		}
+	}
+
+	return out
+}
+
+// statusError converts a parcel error to a gRPC status.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, parcel.ErrBadString):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, parcel.ErrNoServices):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,249 @@
+package rpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/rpc"
+	"dev.freespoke.com/go-package-tracking/rpc/parcelpb"
+)
+
+func client(t *testing.T, srv *rpc.Server) parcelpb.ParcelServiceClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	parcelpb.RegisterParcelServiceServer(s, srv)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.Dial() error %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return parcelpb.NewParcelServiceClient(conn)
+}
+
+func TestTrack(t *testing.T) {
+	c := client(t, rpc.NewServer())
+	ctx := context.Background()
+
+	res, err := c.Track(ctx, &parcelpb.TrackRequest{Number: "1Z5R89390357567127"})
+	if err != nil {
+		t.Fatalf("Track() error %v", err)
+	}
+	if len(res.Matches) != 1 {
+		t.Fatalf("Track() returned %d matches, expected 1", len(res.Matches))
+	}
+	m := res.Matches[0]
+	if m.Courier != "ups" || m.ServiceId != "ups" || m.CheckDigit != "7" || m.Details["ServiceType"] != "03" {
+		t.Errorf("Track() = %v", m)
+	}
+
+	res, err = c.Track(ctx, &parcelpb.TrackRequest{Number: "61299998820821171811"})
+	if err != nil {
+		t.Fatalf("Track() error %v", err)
+	}
+	var partners []string
+	for _, m := range res.Matches {
+		for _, p := range m.Partners {
+			partners = append(partners, m.ServiceId+">"+p.Id)
+		}
+	}
+	if len(partners) != 1 || partners[0] != "fedex_smartpost>usps_91" {
+		t.Errorf("Track() partners = %v, expected fedex_smartpost>usps_91", partners)
+	}
+
+	_, err = c.Track(ctx, &parcelpb.TrackRequest{Number: "été"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Track() error %v, expected %s", err, codes.InvalidArgument)
+	}
+}
+
+func TestFind(t *testing.T) {
+	c := client(t, rpc.NewServer())
+
+	res, err := c.Find(context.Background(), &parcelpb.FindRequest{Text: "order RB123456785GB and 1Z5R89390357567127"})
+	if err != nil {
+		t.Fatalf("Find() error %v", err)
+	}
+	if len(res.Results) != 2 || res.Results[0].Term != "1Z5R89390357567127" || res.Results[1].Term != "RB123456785GB" {
+		t.Errorf("Find() = %v, expected two sorted results", res.Results)
+	}
+}
+
+func TestFindStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		maxTerm int
+		chunks  []string
+		want    []string
+	}{
+		{
+			name:   "whole terms",
+			chunks: []string{"order RB123456785GB ", "and 1Z5R89390357567127\n"},
+			want:   []string{"1Z5R89390357567127", "RB123456785GB"},
+		},
+		{
+			name:   "split terms",
+			chunks: []string{"order RB1234", "56785GB and 1Z5R8939", "0357", "567127"},
+			want:   []string{"1Z5R89390357567127", "RB123456785GB"},
+		},
+		{
+			name:   "duplicates",
+			chunks: []string{"RB123456785GB RB123456785GB ", "RB123456785GB"},
+			want:   []string{"RB123456785GB"},
+		},
+		{
+			name:    "term too long",
+			maxTerm: 8,
+			chunks:  []string{"RB1234567", "85GB"},
+		},
+		{
+			name:   "nothing",
+			chunks: []string{"no numbers ", "here"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := client(t, &rpc.Server{MaxTerm: tt.maxTerm})
+
+			stream, err := c.FindStream(context.Background())
+			if err != nil {
+				t.Fatalf("FindStream() error %v", err)
+			}
+			for _, chunk := range tt.chunks {
+				if err := stream.Send(&parcelpb.FindStreamRequest{Text: chunk}); err != nil {
+					t.Fatalf("FindStream() Send error %v", err)
+				}
+			}
+			if err := stream.CloseSend(); err != nil {
+				t.Fatalf("FindStream() CloseSend error %v", err)
+			}
+
+			var got []string
+			for {
+				res, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				} else if err != nil {
+					t.Fatalf("FindStream() Recv error %v", err)
+				}
+				if len(res.Matches) == 0 {
+					t.Errorf("FindStream() result %q has no matches", res.Term)
+				}
+				got = append(got, res.Term)
+			}
+			sort.Strings(got)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindStream() = %v, expected %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("FindStream() = %v, expected %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// The RPCs return the same results as parcel.Find, including numbers in
+// tracking URLs and their evidence.
+func TestFindMatchesLibrary(t *testing.T) {
+	text := "Order RB123456785GB, shipped. Track it at " +
+		"https://www.ups.com/track?loc=en_US&tracknum=1Z5R89390357567127&requester=ST/ " +
+		"or see http://www.dhl.com/en/express/tracking.html?brand=DHL&AWB=986578788855 today"
+
+	found, err := parcel.Find(text)
+	if err != nil {
+		t.Fatalf("parcel.Find() error %v", err)
+	}
+	want := map[string][]*parcelpb.Tracking{}
+	for term, res := range found {
+		want[term] = rpc.NewTrackings(res)
+	}
+
+	c := client(t, rpc.NewServer())
+
+	res, err := c.Find(context.Background(), &parcelpb.FindRequest{Text: text})
+	if err != nil {
+		t.Fatalf("Find() error %v", err)
+	}
+	got := map[string][]*parcelpb.Tracking{}
+	for _, r := range res.Results {
+		got[r.Term] = r.Matches
+	}
+	if !equalResults(got, want) {
+		t.Errorf("Find() = %v, expected %v", got, want)
+	}
+
+	stream, err := c.FindStream(context.Background())
+	if err != nil {
+		t.Fatalf("FindStream() error %v", err)
+	}
+	for i := 0; i < len(text); i += 7 {
+		end := i + 7
+		if end > len(text) {
+			end = len(text)
+		}
+		if err := stream.Send(&parcelpb.FindStreamRequest{Text: text[i:end]}); err != nil {
+			t.Fatalf("FindStream() Send error %v", err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("FindStream() CloseSend error %v", err)
+	}
+	got = map[string][]*parcelpb.Tracking{}
+	for {
+		r, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatalf("FindStream() Recv error %v", err)
+		}
+		got[r.Term] = r.Matches
+	}
+	if !equalResults(got, want) {
+		t.Errorf("FindStream() = %v, expected %v", got, want)
+	}
+}
+
+func equalResults(a, b map[string][]*parcelpb.Tracking) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for term, x := range a {
+		y, ok := b[term]
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !proto.Equal(x[i], y[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,232 @@
+// Package dhl implements a tracker.StatusProvider for the DHL Shipment
+// Tracking - Unified API.
+package dhl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/timestamp"
+)
+
+const (
+	Courier = "dhl"
+
+	// DefaultBaseURL is the DHL production API.
+	DefaultBaseURL = "https://api-eu.dhl.com"
+
+	trackPath = "/track/shipments"
+
+	// apiKeyHeader carries the DHL API key.
+	apiKeyHeader = "DHL-API-Key"
+)
+
+// Config contains the DHL API key and options.
+type Config struct {
+	APIKey string
+
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+
+	// Language of event descriptions, such as "en" or "de". Defaults to
+	// the DHL default.
+	Language string
+
+	HTTPClient *http.Client
+}
+
+// Provider fetches shipment status from DHL.
+type Provider struct {
+	cfg Config
+}
+
+// New returns a DHL status provider.
+func New(cfg Config) *Provider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &Provider{cfg: cfg}
+}
+
+func (p *Provider) Courier() string {
+	return Courier
+}
+
+// Status fetches the shipment events for a tracking number.
+func (p *Provider) Status(ctx context.Context, t parcel.Tracking) (*tracker.Shipment, error) {
+	q := url.Values{"trackingNumber": {t.TrackingNumber}}
+	if p.cfg.Language != "" {
+		q.Set("language", p.cfg.Language)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.BaseURL+trackPath+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(apiKeyHeader, p.cfg.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.StatusCode, body)
+	}
+
+	var r trackResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("dhl: decoding response: %w", err)
+	}
+
+	return r.shipment(t.TrackingNumber)
+}
+
+// apiError converts a problem details response to a tracker error.
+func apiError(status int, body []byte) error {
+	var r struct {
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	}
+	_ = json.Unmarshal(body, &r)
+
+	e := &tracker.APIError{
+		Courier:    Courier,
+		StatusCode: status,
+		Message:    r.Detail,
+	}
+	if e.Message == "" {
+		e.Message = r.Title
+	}
+	if status == http.StatusNotFound {
+		e.Err = tracker.ErrNotFound
+	}
+
+	return e
+}
+
+type trackResponse struct {
+	Shipments []struct {
+		ID                      string  `json:"id"`
+		Service                 string  `json:"service"`
+		Status                  event   `json:"status"`
+		EstimatedTimeOfDelivery string  `json:"estimatedTimeOfDelivery"`
+		Events                  []event `json:"events"`
+	} `json:"shipments"`
+}
+
+type event struct {
+	Timestamp string `json:"timestamp"`
+	Location  struct {
+		Address struct {
+			CountryCode     string `json:"countryCode"`
+			PostalCode      string `json:"postalCode"`
+			AddressLocality string `json:"addressLocality"`
+		} `json:"address"`
+	} `json:"location"`
+	StatusCode  string `json:"statusCode"`
+	Status      string `json:"status"`
+	Description string `json:"description"`
+}
+
+// statusCodes maps DHL status codes to normalized statuses.
+var statusCodes = map[string]tracker.Status{
+	"pre-transit": tracker.StatusLabelCreated,
+	"transit":     tracker.StatusInTransit,
+	"delivered":   tracker.StatusDelivered,
+	"failure":     tracker.StatusException,
+}
+
+// status normalizes an event. DHL has no status code for out for delivery or
+// returned shipments, so they are detected from the description.
+func (e event) status() tracker.Status {
+	s := statusCodes[e.StatusCode]
+	text := strings.ToLower(e.Status + " " + e.Description)
+
+	switch {
+	case strings.Contains(text, "returned to") || strings.Contains(text, "return to sender"):
+		return tracker.StatusReturned
+	case s == tracker.StatusInTransit && isOutForDelivery(text):
+		return tracker.StatusOutForDelivery
+	}
+
+	return s
+}
+
+// outForDelivery contains the phrases DHL uses for out for delivery events.
+var outForDelivery = []string{
+	"out for delivery",
+	"with courier for delivery",
+	"with delivery courier",
+}
+
+func isOutForDelivery(text string) bool {
+	for _, v := range outForDelivery {
+		if strings.Contains(text, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e event) event() tracker.Event {
+	addr := e.Location.Address
+	desc := e.Description
+	if desc == "" {
+		desc = e.Status
+	}
+
+	return tracker.Event{
+		Status:      e.status(),
+		Code:        e.StatusCode,
+		Description: desc,
+		Location: tracker.Location{
+			City:       addr.AddressLocality,
+			PostalCode: addr.PostalCode,
+			Country:    addr.CountryCode,
+		},
+		Time: timestamp.Parse(e.Timestamp),
+	}
+}
+
+// shipment converts the response to a normalized shipment.
+func (r trackResponse) shipment(num string) (*tracker.Shipment, error) {
+	if len(r.Shipments) == 0 {
+		return nil, tracker.ErrNotFound
+	}
+
+	s := r.Shipments[0]
+	out := &tracker.Shipment{
+		Courier:        Courier,
+		TrackingNumber: num,
+		Status:         s.Status.status(),
+		Events:         make([]tracker.Event, 0, len(s.Events)),
+		ETA:            timestamp.Parse(s.EstimatedTimeOfDelivery),
+	}
+	for _, e := range s.Events {
+		out.Events = append(out.Events, e.event())
+	}
+	out.Normalize()
+
+	return out, nil
+}
@@ -0,0 +1,91 @@
+package dhl_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/dhl"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/replay"
+)
+
+func TestStatus(t *testing.T) {
+	srv := replay.NewServer(t, replay.Config{
+		APIKeyHeader: "DHL-API-Key",
+		APIKey:       "key",
+		Number: func(r *http.Request) string {
+			return r.URL.Query().Get("trackingNumber")
+		},
+		Dir: "testdata",
+		Status: map[string]int{
+			"1234567890": http.StatusNotFound,
+			"0000000000": http.StatusTooManyRequests,
+		},
+	})
+	p := dhl.New(dhl.Config{
+		APIKey:     "key",
+		BaseURL:    srv.URL,
+		HTTPClient: srv.Client(),
+	})
+
+	tests := []struct {
+		num       string
+		status    tracker.Status
+		wantErr   error
+		temporary bool
+	}{
+		{num: "JVGL0999999990", status: tracker.StatusDelivered},
+		{num: "3318810025", status: tracker.StatusReturned},
+		{num: "73891051146", status: tracker.StatusInTransit},
+		{num: "1234567890", wantErr: tracker.ErrNotFound},
+		{num: "0000000000", temporary: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.num, func(t *testing.T) {
+			got, err := p.Status(context.Background(), parcel.Tracking{Courier: dhl.Courier, TrackingNumber: tt.num})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Status() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if tt.temporary {
+				var apiErr *tracker.APIError
+				if !errors.As(err, &apiErr) || !apiErr.Temporary() {
+					t.Errorf("Status() error = %v, want a temporary *tracker.APIError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Status() error %v", err)
+			}
+			if got.Status != tt.status {
+				t.Errorf("Status() status = %s, want %s", got.Status, tt.status)
+			}
+			replay.Golden(t, filepath.Join("testdata", tt.num+".golden"), got)
+		})
+	}
+}
+
+func TestBadAPIKey(t *testing.T) {
+	srv := replay.NewServer(t, replay.Config{
+		APIKeyHeader: "DHL-API-Key",
+		APIKey:       "key",
+		Number: func(r *http.Request) string {
+			return r.URL.Query().Get("trackingNumber")
+		},
+		Dir: "testdata",
+	})
+	p := dhl.New(dhl.Config{APIKey: "wrong", BaseURL: srv.URL, HTTPClient: srv.Client()})
+
+	_, err := p.Status(context.Background(), parcel.Tracking{TrackingNumber: "JVGL0999999990"})
+	var apiErr *tracker.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Status() error = %v, want 401", err)
+	}
+}
@@ -0,0 +1,79 @@
+package tracker
+
+import (
+	"context"
+	"sync"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+// Fake is an in-memory status provider for tests.
+type Fake struct {
+	courier string
+
+	mu        sync.Mutex
+	shipments map[string]Shipment
+	errs      map[string]error
+	calls     map[string]int
+}
+
+// NewFake returns an empty fake provider for a courier code.
+func NewFake(courier string) *Fake {
+	return &Fake{
+		courier:   courier,
+		shipments: make(map[string]Shipment),
+		errs:      make(map[string]error),
+		calls:     make(map[string]int),
+	}
+}
+
+// Set stores the shipment returned for a tracking number.
+func (f *Fake) Set(trackingNumber string, s Shipment) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.shipments[trackingNumber] = s
+	delete(f.errs, trackingNumber)
+}
+
+// SetError makes the provider fail for a tracking number.
+func (f *Fake) SetError(trackingNumber string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errs[trackingNumber] = err
+}
+
+// Calls returns how many times a tracking number was requested.
+func (f *Fake) Calls(trackingNumber string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[trackingNumber]
+}
+
+func (f *Fake) Courier() string {
+	return f.courier
+}
+
+// Status returns a copy of the stored shipment, or ErrNotFound.
+func (f *Fake) Status(ctx context.Context, t parcel.Tracking) (*Shipment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[t.TrackingNumber]++
+	if err, ok := f.errs[t.TrackingNumber]; ok {
+		return nil, err
+	}
+	s, ok := f.shipments[t.TrackingNumber]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s.Events = append([]Event(nil), s.Events...)
+
+	return &s, nil
+}
@@ -0,0 +1,301 @@
+// Package fedex implements a tracker.StatusProvider for the FedEx Track API.
+package fedex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/oauth"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/timestamp"
+)
+
+const (
+	Courier = "fedex"
+
+	// DefaultBaseURL is the FedEx production API.
+	DefaultBaseURL = "https://apis.fedex.com"
+
+	// TestBaseURL is the FedEx sandbox.
+	TestBaseURL = "https://apis-sandbox.fedex.com"
+
+	tokenPath = "/oauth/token"
+	trackPath = "/track/v1/trackingnumbers"
+
+	// Error codes returned by the Track API.
+	codeNotFound = "TRACKING.TRACKINGNUMBER.NOTFOUND"
+	codeInvalid  = "TRACKING.TRACKINGNUMBER.INVALID"
+)
+
+// Config contains the FedEx API credentials and options.
+type Config struct {
+	ClientID     string // API key
+	ClientSecret string // secret key
+
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+
+	HTTPClient *http.Client
+}
+
+// Provider fetches shipment status from FedEx.
+type Provider struct {
+	cfg   Config
+	token *oauth.Source
+}
+
+// New returns a FedEx status provider.
+func New(cfg Config) *Provider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &Provider{
+		cfg: cfg,
+		token: &oauth.Source{
+			Courier:      Courier,
+			TokenURL:     cfg.BaseURL + tokenPath,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			InParams:     true,
+			Client:       cfg.HTTPClient,
+		},
+	}
+}
+
+func (p *Provider) Courier() string {
+	return Courier
+}
+
+type trackRequest struct {
+	IncludeDetailedScans bool           `json:"includeDetailedScans"`
+	TrackingInfo         []trackingInfo `json:"trackingInfo"`
+}
+
+type trackingInfo struct {
+	TrackingNumberInfo struct {
+		TrackingNumber string `json:"trackingNumber"`
+	} `json:"trackingNumberInfo"`
+}
+
+// Status fetches the scan events for a tracking number.
+func (p *Provider) Status(ctx context.Context, t parcel.Tracking) (*tracker.Shipment, error) {
+	tok, err := p.token.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var info trackingInfo
+	info.TrackingNumberInfo.TrackingNumber = t.TrackingNumber
+
+	buf, err := json.Marshal(trackRequest{
+		IncludeDetailedScans: true,
+		TrackingInfo:         []trackingInfo{info},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.cfg.BaseURL+trackPath, bytes.NewReader(buf))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	res, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// The token was revoked or expired early; fetch a new one next time.
+		p.token.Invalidate()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.StatusCode, res)
+	}
+
+	var r trackResponse
+	if err := json.Unmarshal(res, &r); err != nil {
+		return nil, fmt.Errorf("fedex: decoding response: %w", err)
+	}
+
+	return r.shipment(t.TrackingNumber)
+}
+
+type apiErr struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiError converts an error response to a tracker error.
+func apiError(status int, body []byte) error {
+	var r struct {
+		Errors []apiErr `json:"errors"`
+	}
+	_ = json.Unmarshal(body, &r)
+
+	e := &tracker.APIError{Courier: Courier, StatusCode: status}
+	if len(r.Errors) > 0 {
+		e.Code = r.Errors[0].Code
+		e.Message = r.Errors[0].Message
+	}
+	e.Err = sentinel(e.Code)
+
+	return e
+}
+
+func sentinel(code string) error {
+	switch code {
+	case codeNotFound:
+		return tracker.ErrNotFound
+	case codeInvalid:
+		return tracker.ErrInvalid
+	}
+
+	return nil
+}
+
+type trackResponse struct {
+	Output struct {
+		CompleteTrackResults []struct {
+			TrackingNumber string        `json:"trackingNumber"`
+			TrackResults   []trackResult `json:"trackResults"`
+		} `json:"completeTrackResults"`
+	} `json:"output"`
+}
+
+type trackResult struct {
+	LatestStatusDetail struct {
+		Code        string `json:"code"`
+		DerivedCode string `json:"derivedCode"`
+		Description string `json:"description"`
+	} `json:"latestStatusDetail"`
+	DateAndTimes []struct {
+		Type     string `json:"type"`
+		DateTime string `json:"dateTime"`
+	} `json:"dateAndTimes"`
+	EstimatedDeliveryTimeWindow struct {
+		Window struct {
+			Ends string `json:"ends"`
+		} `json:"window"`
+	} `json:"estimatedDeliveryTimeWindow"`
+	ScanEvents []struct {
+		Date                 string `json:"date"`
+		EventType            string `json:"eventType"`
+		EventDescription     string `json:"eventDescription"`
+		ExceptionCode        string `json:"exceptionCode"`
+		ExceptionDescription string `json:"exceptionDescription"`
+		ScanLocation         struct {
+			City                string `json:"city"`
+			StateOrProvinceCode string `json:"stateOrProvinceCode"`
+			PostalCode          string `json:"postalCode"`
+			CountryCode         string `json:"countryCode"`
+		} `json:"scanLocation"`
+		DerivedStatusCode string `json:"derivedStatusCode"`
+	} `json:"scanEvents"`
+	Error *apiErr `json:"error"`
+}
+
+// eventTypes maps FedEx scan event types to normalized statuses.
+var eventTypes = map[string]tracker.Status{
+	"OC": tracker.StatusLabelCreated, // shipment information sent to FedEx
+	"PU": tracker.StatusInTransit,    // picked up
+	"AR": tracker.StatusInTransit,    // arrived at location
+	"AF": tracker.StatusInTransit,    // at local FedEx facility
+	"DP": tracker.StatusInTransit,    // departed location
+	"IT": tracker.StatusInTransit,
+	"AA": tracker.StatusInTransit, // at airport
+	"AD": tracker.StatusInTransit, // at delivery
+	"CC": tracker.StatusInTransit, // cleared customs
+	"HL": tracker.StatusInTransit, // hold at location
+	"OD": tracker.StatusOutForDelivery,
+	"DL": tracker.StatusDelivered,
+	"DE": tracker.StatusException, // delivery exception
+	"SE": tracker.StatusException, // shipment exception
+	"CD": tracker.StatusException, // clearance delay
+	"CA": tracker.StatusException, // shipment cancelled
+	"RS": tracker.StatusReturned,  // return to shipper
+}
+
+// shipment converts the response to a normalized shipment.
+func (r trackResponse) shipment(num string) (*tracker.Shipment, error) {
+	for _, c := range r.Output.CompleteTrackResults {
+		for _, res := range c.TrackResults {
+			if res.Error != nil && res.Error.Code != "" {
+				return nil, &tracker.APIError{
+					Courier:    Courier,
+					StatusCode: http.StatusOK,
+					Code:       res.Error.Code,
+					Message:    res.Error.Message,
+					Err:        sentinel(res.Error.Code),
+				}
+			}
+			return res.shipment(num), nil
+		}
+	}
+
+	return nil, tracker.ErrNotFound
+}
+
+func (res trackResult) shipment(num string) *tracker.Shipment {
+	out := &tracker.Shipment{
+		Courier:        Courier,
+		TrackingNumber: num,
+		Status:         eventTypes[res.LatestStatusDetail.DerivedCode],
+		Events:         make([]tracker.Event, 0, len(res.ScanEvents)),
+	}
+	if out.Status == tracker.StatusUnknown {
+		out.Status = eventTypes[res.LatestStatusDetail.Code]
+	}
+
+	for _, d := range res.DateAndTimes {
+		if d.Type == "ESTIMATED_DELIVERY" {
+			out.ETA = timestamp.Parse(d.DateTime)
+		}
+	}
+	if out.ETA.IsZero() {
+		out.ETA = timestamp.Parse(res.EstimatedDeliveryTimeWindow.Window.Ends)
+	}
+
+	for _, e := range res.ScanEvents {
+		desc := e.EventDescription
+		if e.ExceptionDescription != "" {
+			desc += ": " + e.ExceptionDescription
+		}
+		loc := e.ScanLocation
+		out.Events = append(out.Events, tracker.Event{
+			Status:      eventTypes[e.EventType],
+			Code:        e.EventType,
+			Description: desc,
+			Location: tracker.Location{
+				City:       loc.City,
+				State:      loc.StateOrProvinceCode,
+				PostalCode: loc.PostalCode,
+				Country:    loc.CountryCode,
+			},
+			Time: timestamp.Parse(e.Date),
+		})
+	}
+	out.Normalize()
+
+	return out
+}
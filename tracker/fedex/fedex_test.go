@@ -0,0 +1,114 @@
+package fedex_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/fedex"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/replay"
+)
+
+// number extracts the tracking number from a track request body.
+func number(r *http.Request) string {
+	var body struct {
+		TrackingInfo []struct {
+			TrackingNumberInfo struct {
+				TrackingNumber string `json:"trackingNumber"`
+			} `json:"trackingNumberInfo"`
+		} `json:"trackingInfo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || len(body.TrackingInfo) == 0 {
+		return ""
+	}
+
+	return body.TrackingInfo[0].TrackingNumberInfo.TrackingNumber
+}
+
+func TestStatus(t *testing.T) {
+	srv := replay.NewServer(t, replay.Config{
+		TokenPath:    "/oauth/token",
+		ClientID:     "key",
+		ClientSecret: "secret",
+		InParams:     true,
+		Number:       number,
+		Dir:          "testdata",
+		Status:       map[string]int{"000000000000": http.StatusBadRequest},
+	})
+	p := fedex.New(fedex.Config{
+		ClientID:     "key",
+		ClientSecret: "secret",
+		BaseURL:      srv.URL,
+		HTTPClient:   srv.Client(),
+	})
+
+	tests := []struct {
+		num     string
+		status  tracker.Status
+		wantErr error
+	}{
+		{num: "986578788855", status: tracker.StatusDelivered},
+		{num: "477179081230", status: tracker.StatusException},
+		{num: "799531274483", wantErr: tracker.ErrNotFound},
+		{num: "000000000000", wantErr: tracker.ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.num, func(t *testing.T) {
+			got, err := p.Status(context.Background(), parcel.Tracking{Courier: fedex.Courier, TrackingNumber: tt.num})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Status() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Status() error %v", err)
+			}
+			if got.Status != tt.status {
+				t.Errorf("Status() status = %s, want %s", got.Status, tt.status)
+			}
+			replay.Golden(t, filepath.Join("testdata", tt.num+".golden"), got)
+		})
+	}
+}
+
+func TestRevokedToken(t *testing.T) {
+	srv := replay.NewServer(t, replay.Config{
+		TokenPath:    "/oauth/token",
+		ClientID:     "key",
+		ClientSecret: "secret",
+		InParams:     true,
+		Number:       number,
+		Dir:          "testdata",
+	})
+	p := fedex.New(fedex.Config{
+		ClientID:     "key",
+		ClientSecret: "secret",
+		BaseURL:      srv.URL,
+		HTTPClient:   srv.Client(),
+	})
+	tr := parcel.Tracking{Courier: "fedex", TrackingNumber: "986578788855"}
+
+	if _, err := p.Status(context.Background(), tr); err != nil {
+		t.Fatalf("Status() error %v", err)
+	}
+	srv.Revoke()
+
+	_, err := p.Status(context.Background(), tr)
+	var apiErr *tracker.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Status() error = %v, want a 401 *tracker.APIError", err)
+	}
+	if _, err := p.Status(context.Background(), tr); err != nil {
+		t.Errorf("Status() after a 401 error %v", err)
+	}
+	if n := srv.Tokens(); n != 2 {
+		t.Errorf("expected a new token after the 401, got %d token requests", n)
+	}
+}
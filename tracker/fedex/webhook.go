@@ -0,0 +1,74 @@
+package fedex
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"dev.freespoke.com/go-package-tracking/tracker/webhook"
+)
+
+// signatureHeader carries the base64 HMAC-SHA256 of the body, keyed with
+// the webhook project's security token.
+const signatureHeader = "X-Fdx-Signature"
+
+// Webhook parses FedEx tracking webhook notifications.
+type Webhook struct {
+	token []byte
+}
+
+// NewWebhook returns a webhook.Parser verifying notifications with the
+// webhook project's security token.
+func NewWebhook(securityToken string) *Webhook {
+	return &Webhook{token: []byte(securityToken)}
+}
+
+func (w *Webhook) Courier() string {
+	return Courier
+}
+
+func (w *Webhook) Verify(r *http.Request, body []byte) error {
+	got, err := base64.StdEncoding.DecodeString(r.Header.Get(signatureHeader))
+	if err != nil || len(w.token) == 0 {
+		return webhook.ErrSignature
+	}
+
+	mac := hmac.New(sha256.New, w.token)
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return webhook.ErrSignature
+	}
+
+	return nil
+}
+
+type notification struct {
+	TrackingEventID string      `json:"trackingEventId"`
+	TrackingNumber  string      `json:"trackingNumber"`
+	TrackResults    trackResult `json:"trackResults"`
+}
+
+// Parse decodes a notification. Its track results use the same format as
+// the Track API.
+func (w *Webhook) Parse(body []byte) ([]webhook.Notification, error) {
+	var n notification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, fmt.Errorf("fedex: decoding notification: %w", err)
+	}
+	if n.TrackingNumber == "" {
+		return nil, fmt.Errorf("fedex: notification without tracking number")
+	}
+
+	s := n.TrackResults.shipment(n.TrackingNumber)
+
+	return []webhook.Notification{{
+		ID:             n.TrackingEventID,
+		TrackingNumber: n.TrackingNumber,
+		Status:         s.Status,
+		ETA:            s.ETA,
+		Events:         s.Events,
+	}}, nil
+}
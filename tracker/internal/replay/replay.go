@@ -0,0 +1,162 @@
+// Package replay provides stand-in courier API servers that replay canned
+// responses, and golden file helpers for provider tests.
+package replay
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// Config describes the courier API being replayed.
+type Config struct {
+	// TokenPath serves OAuth client credentials tokens if set.
+	TokenPath    string
+	ClientID     string
+	ClientSecret string
+
+	// InParams expects the credentials as form values instead of HTTP basic
+	// authentication.
+	InParams bool
+
+	// TokenStatus fails token requests with the HTTP status if set.
+	TokenStatus int
+
+	// APIKeyHeader requires an API key header on every request if set.
+	APIKeyHeader string
+	APIKey       string
+
+	// Number extracts the tracking number from a tracking request.
+	Number func(r *http.Request) string
+
+	// Dir contains the canned responses, named <number>.json.
+	Dir string
+
+	// Status is the HTTP status replayed for a number. Defaults to 200.
+	Status map[string]int
+}
+
+const accessToken = "replay-token"
+
+// Server is a running stand-in courier API.
+type Server struct {
+	*httptest.Server
+
+	tokens  int32
+	revoked int32
+}
+
+// Revoke rejects the access tokens issued so far.
+func (s *Server) Revoke() {
+	atomic.StoreInt32(&s.revoked, atomic.LoadInt32(&s.tokens))
+}
+
+// accessToken returns the current access token.
+func (s *Server) accessToken() string {
+	return accessToken + "-" + strconv.Itoa(int(atomic.LoadInt32(&s.revoked)))
+}
+
+// Tokens returns the number of access tokens issued.
+func (s *Server) Tokens() int {
+	return int(atomic.LoadInt32(&s.tokens))
+}
+
+// NewServer starts a server replaying responses for cfg. It is closed when
+// the test completes.
+func NewServer(t *testing.T, cfg Config) *Server {
+	t.Helper()
+
+	s := new(Server)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.TokenPath != "" && r.URL.Path == cfg.TokenPath {
+			s.token(w, r, cfg)
+			return
+		}
+
+		if cfg.TokenPath != "" && r.Header.Get("Authorization") != "Bearer "+s.accessToken() {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		if cfg.APIKeyHeader != "" && r.Header.Get(cfg.APIKeyHeader) != cfg.APIKey {
+			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+
+		num := cfg.Number(r)
+		buf, err := os.ReadFile(filepath.Join(cfg.Dir, filepath.Base(num)+".json"))
+		if err != nil {
+			http.Error(w, `{"error":"no canned response"}`, http.StatusNotFound)
+			return
+		}
+
+		status := http.StatusOK
+		if v, ok := cfg.Status[num]; ok {
+			status = v
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write(buf)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request, cfg Config) {
+	if cfg.TokenStatus != 0 {
+		http.Error(w, `{"error":"unavailable"}`, cfg.TokenStatus)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+
+	id, secret, _ := r.BasicAuth()
+	if cfg.InParams {
+		id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if id != cfg.ClientID || secret != cfg.ClientSecret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+
+	atomic.AddInt32(&s.tokens, 1)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"access_token":"` + s.accessToken() + `","token_type":"Bearer","expires_in":3599}`))
+}
+
+// Golden compares v, encoded as indented JSON, to the golden file at path.
+// Run tests with -update to rewrite the golden files.
+func Golden(t *testing.T, path string, v any) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("encoding %s: %v", path, err)
+	}
+	got = append(got, '\n')
+
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %s: %v (run with -update to create it)", path, err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s mismatch\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
@@ -0,0 +1,20 @@
+// Package timestamp parses the timestamps returned by the courier APIs.
+package timestamp
+
+import "time"
+
+// layouts are tried in order. Couriers may omit the time zone.
+var layouts = []string{time.RFC3339, "2006-01-02T15:04:05"}
+
+// Parse parses an RFC 3339 timestamp, with or without a time zone. Without
+// one, the time is taken as UTC. It returns the zero time if s can't be
+// parsed.
+func Parse(s string) time.Time {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,273 @@
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+var ErrTransition = errors.New("illegal status transition")
+
+// transitions lists the statuses a shipment may move to from each status.
+// A status may always stay the same.
+var transitions = map[Status][]Status{
+	StatusUnknown:        {StatusLabelCreated, StatusInTransit, StatusOutForDelivery, StatusDelivered, StatusException, StatusReturned},
+	StatusLabelCreated:   {StatusInTransit, StatusOutForDelivery, StatusDelivered, StatusException, StatusReturned},
+	StatusInTransit:      {StatusOutForDelivery, StatusDelivered, StatusException, StatusReturned},
+	StatusOutForDelivery: {StatusInTransit, StatusDelivered, StatusException, StatusReturned},
+	StatusException:      {StatusInTransit, StatusOutForDelivery, StatusDelivered, StatusReturned},
+	StatusDelivered:      {StatusException, StatusReturned},
+	StatusReturned:       {},
+}
+
+// CanTransition reports whether a shipment may move between two statuses.
+// An unknown status reported by a courier never replaces a known one.
+func CanTransition(from, to Status) bool {
+	if from == to {
+		return true
+	}
+	for _, v := range transitions[from] {
+		if v == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ChangeKind identifies the type of a Change.
+type ChangeKind int
+
+const (
+	// ChangeEvent is a new courier event.
+	ChangeEvent ChangeKind = iota
+
+	// ChangeStatus is a status change not covered by a more specific kind.
+	ChangeStatus
+
+	ChangeDelivered
+	ChangeException
+	ChangeReturned
+
+	// ChangeETA is a new or moved estimated delivery time.
+	ChangeETA
+)
+
+var changeNames = [...]string{
+	ChangeEvent:     "event",
+	ChangeStatus:    "status",
+	ChangeDelivered: "delivered",
+	ChangeException: "exception",
+	ChangeReturned:  "returned",
+	ChangeETA:       "eta",
+}
+
+func (k ChangeKind) String() string {
+	if k < 0 || int(k) >= len(changeNames) {
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+
+	return changeNames[k]
+}
+
+// Change describes a difference between two updates of a shipment.
+type Change struct {
+	Kind           ChangeKind
+	Courier        string
+	TrackingNumber string
+
+	// From and To are set for status changes.
+	From Status
+	To   Status
+
+	// Event is set for ChangeEvent.
+	Event Event
+
+	// OldETA and NewETA are set for ChangeETA. OldETA is zero if the ETA
+	// wasn't known before.
+	OldETA time.Time
+	NewETA time.Time
+}
+
+// Monitor stores the last known status of each shipment and reports what
+// changed when a shipment is updated.
+type Monitor struct {
+	mu        sync.Mutex
+	shipments map[string]*Shipment
+	subs      []func(Change)
+}
+
+// NewMonitor returns an empty monitor.
+func NewMonitor() *Monitor {
+	return &Monitor{
+		shipments: make(map[string]*Shipment),
+	}
+}
+
+// Subscribe registers a function called for every change. Subscribers are
+// called in order, after the update is stored.
+func (m *Monitor) Subscribe(fn func(Change)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subs = append(m.subs, fn)
+}
+
+// Shipment returns a copy of the last known status of a shipment.
+func (m *Monitor) Shipment(courier, trackingNumber string) (Shipment, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.shipments[shipmentKey(courier, trackingNumber)]
+	if !ok {
+		return Shipment{}, false
+	}
+
+	return s.clone(), true
+}
+
+// Update merges a newly fetched shipment into the stored one and notifies
+// subscribers of the changes.
+// Events already seen are ignored. An update moving the shipment to a status
+// that isn't allowed from its current status returns ErrTransition and
+// keeps the stored status and ETA; its new events are still merged and
+// returned with the error. The update itself isn't modified.
+func (m *Monitor) Update(s *Shipment) ([]Change, error) {
+	m.mu.Lock()
+
+	key := shipmentKey(s.Courier, s.TrackingNumber)
+	prev, ok := m.shipments[key]
+	if !ok {
+		prev = &Shipment{Courier: s.Courier, TrackingNumber: s.TrackingNumber}
+	}
+
+	next, changes, err := merge(prev, s)
+	m.shipments[key] = next
+	subs := append([]func(Change){}, m.subs...)
+
+	m.mu.Unlock()
+
+	for _, c := range changes {
+		for _, fn := range subs {
+			fn(c)
+		}
+	}
+
+	return changes, err
+}
+
+// Restore seeds the monitor with a previously stored shipment, such as one
+// loaded from a store. Subscribers aren't notified.
+func (m *Monitor) Restore(s Shipment) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := s.clone()
+	m.shipments[shipmentKey(s.Courier, s.TrackingNumber)] = &c
+}
+
+// Refresh fetches the status of a tracking result from the registry and
+// updates the monitor with it.
+func (m *Monitor) Refresh(ctx context.Context, r *Registry, t parcel.Tracking) ([]Change, error) {
+	s, err := r.Status(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.Update(s)
+}
+
+// Forget removes a shipment from the monitor.
+func (m *Monitor) Forget(courier, trackingNumber string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.shipments, shipmentKey(courier, trackingNumber))
+}
+
+// merge returns the result of applying an update to a shipment and the
+// changes between them. An illegal status transition keeps the status and
+// ETA of prev and returns ErrTransition with the merged events.
+func merge(prev, s *Shipment) (*Shipment, []Change, error) {
+	update := s.clone()
+	update.Normalize()
+
+	next := prev.clone()
+	change := func(c Change) Change {
+		c.Courier = next.Courier
+		c.TrackingNumber = next.TrackingNumber
+		return c
+	}
+
+	status := update.Status
+	if status == StatusUnknown {
+		status = prev.Status
+	}
+	var err error
+	if !CanTransition(prev.Status, status) {
+		err = fmt.Errorf("%w: %s %s from %s to %s", ErrTransition, prev.Courier, prev.TrackingNumber, prev.Status, status)
+		status = prev.Status
+	}
+
+	changes := make([]Change, 0)
+
+	seen := make(map[string]bool, len(prev.Events))
+	for _, e := range prev.Events {
+		seen[e.key()] = true
+	}
+	for _, e := range update.Events {
+		if seen[e.key()] {
+			continue
+		}
+		seen[e.key()] = true
+		next.Events = append(next.Events, e)
+		changes = append(changes, change(Change{Kind: ChangeEvent, Event: e}))
+	}
+	next.Status = status
+	next.Normalize()
+
+	if status != prev.Status {
+		kind := ChangeStatus
+		switch status {
+		case StatusDelivered:
+			kind = ChangeDelivered
+		case StatusException:
+			kind = ChangeException
+		case StatusReturned:
+			kind = ChangeReturned
+		}
+		changes = append(changes, change(Change{Kind: kind, From: prev.Status, To: status}))
+	}
+
+	if err == nil && !update.ETA.IsZero() && !update.ETA.Equal(prev.ETA) {
+		next.ETA = update.ETA
+		changes = append(changes, change(Change{Kind: ChangeETA, OldETA: prev.ETA, NewETA: update.ETA}))
+	}
+
+	return &next, changes, err
+}
+
+// key identifies an event for deduplication. Courier event IDs are used when
+// available, otherwise the time, location and code.
+func (e Event) key() string {
+	if e.ID != "" {
+		return "id:" + e.ID
+	}
+
+	return e.Time.UTC().Format(time.RFC3339Nano) + "|" + e.Location.String() + "|" + e.Code
+}
+
+func (s *Shipment) clone() Shipment {
+	out := *s
+	out.Events = append([]Event(nil), s.Events...)
+
+	return out
+}
+
+func shipmentKey(courier, trackingNumber string) string {
+	return courier + ":" + trackingNumber
+}
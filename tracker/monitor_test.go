@@ -0,0 +1,230 @@
+package tracker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+)
+
+func TestMonitorUpdate(t *testing.T) {
+	start := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	origin := tracker.Location{City: "NEWARK", State: "NJ"}
+	dest := tracker.Location{City: "AUSTIN", State: "TX"}
+
+	labeled := tracker.Event{Status: tracker.StatusLabelCreated, Code: "OC", Location: origin, Time: start}
+	picked := tracker.Event{Status: tracker.StatusInTransit, Code: "PU", Location: origin, Time: start.Add(8 * time.Hour)}
+	ofd := tracker.Event{Status: tracker.StatusOutForDelivery, Code: "OD", Location: dest, Time: start.Add(48 * time.Hour)}
+	exception := tracker.Event{Status: tracker.StatusException, Code: "DE", Location: dest, Time: start.Add(54 * time.Hour)}
+	delivered := tracker.Event{Status: tracker.StatusDelivered, Code: "DL", Location: dest, Time: start.Add(72 * time.Hour)}
+
+	m := tracker.NewMonitor()
+	var got []tracker.Change
+	m.Subscribe(func(c tracker.Change) { got = append(got, c) })
+
+	steps := []struct {
+		name   string
+		events []tracker.Event
+		eta    time.Time
+		want   []tracker.ChangeKind
+	}{
+		{
+			name:   "first update",
+			events: []tracker.Event{labeled},
+			want:   []tracker.ChangeKind{tracker.ChangeEvent, tracker.ChangeStatus},
+		},
+		{
+			name:   "picked up with eta",
+			events: []tracker.Event{labeled, picked},
+			eta:    start.Add(72 * time.Hour),
+			want:   []tracker.ChangeKind{tracker.ChangeEvent, tracker.ChangeStatus, tracker.ChangeETA},
+		},
+		{
+			name:   "no change",
+			events: []tracker.Event{picked, labeled},
+			eta:    start.Add(72 * time.Hour),
+			want:   []tracker.ChangeKind{},
+		},
+		{
+			name:   "exception moves eta",
+			events: []tracker.Event{labeled, picked, ofd, exception},
+			eta:    start.Add(96 * time.Hour),
+			want:   []tracker.ChangeKind{tracker.ChangeEvent, tracker.ChangeEvent, tracker.ChangeException, tracker.ChangeETA},
+		},
+		{
+			name:   "delivered",
+			events: []tracker.Event{delivered},
+			want:   []tracker.ChangeKind{tracker.ChangeEvent, tracker.ChangeDelivered},
+		},
+	}
+
+	for _, step := range steps {
+		got = nil
+		changes, err := m.Update(&tracker.Shipment{
+			Courier:        "fedex",
+			TrackingNumber: "986578788855",
+			Events:         step.events,
+			ETA:            step.eta,
+		})
+		if err != nil {
+			t.Fatalf("%s: Update() error %v", step.name, err)
+		}
+		if len(changes) != len(step.want) || len(got) != len(step.want) {
+			t.Fatalf("%s: Update() changes = %v, subscriber got %d, want %v", step.name, changes, len(got), step.want)
+		}
+		for i, c := range changes {
+			if c.Kind != step.want[i] {
+				t.Errorf("%s: change %d = %s, want %s", step.name, i, c.Kind, step.want[i])
+			}
+			if c.TrackingNumber != "986578788855" || c.Courier != "fedex" {
+				t.Errorf("%s: change %d identity = %s %s", step.name, i, c.Courier, c.TrackingNumber)
+			}
+		}
+	}
+
+	s, ok := m.Shipment("fedex", "986578788855")
+	if !ok {
+		t.Fatal("Shipment() expected the stored shipment")
+	}
+	if s.Status != tracker.StatusDelivered || len(s.Events) != 5 {
+		t.Errorf("Shipment() = %s with %d events, want delivered with 5", s.Status, len(s.Events))
+	}
+	if !s.ETA.Equal(start.Add(96 * time.Hour)) {
+		t.Errorf("Shipment() ETA = %v", s.ETA)
+	}
+
+	// A stale update can't move a delivered shipment back in transit.
+	_, err := m.Update(&tracker.Shipment{
+		Courier:        "fedex",
+		TrackingNumber: "986578788855",
+		Events:         []tracker.Event{labeled, picked},
+	})
+	if !errors.Is(err, tracker.ErrTransition) {
+		t.Errorf("Update() error = %v, want %v", err, tracker.ErrTransition)
+	}
+	if s, _ := m.Shipment("fedex", "986578788855"); s.Status != tracker.StatusDelivered {
+		t.Errorf("Update() rejected update changed status to %s", s.Status)
+	}
+}
+
+func TestMonitorRejectedEvents(t *testing.T) {
+	now := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	m := tracker.NewMonitor()
+
+	delivered := tracker.Event{ID: "e2", Status: tracker.StatusDelivered, Time: now}
+	if _, err := m.Update(&tracker.Shipment{Courier: "dhl", TrackingNumber: "1", Events: []tracker.Event{delivered}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new scan arrives whose status can't follow delivery.
+	scanned := tracker.Event{ID: "e1", Status: tracker.StatusInTransit, Time: now.Add(time.Hour)}
+	update := &tracker.Shipment{
+		Courier:        "dhl",
+		TrackingNumber: "1",
+		ETA:            now.Add(2 * time.Hour),
+		Events:         []tracker.Event{scanned, delivered},
+	}
+	changes, err := m.Update(update)
+	if !errors.Is(err, tracker.ErrTransition) {
+		t.Errorf("Update() error = %v, want %v", err, tracker.ErrTransition)
+	}
+	if len(changes) != 1 || changes[0].Kind != tracker.ChangeEvent || changes[0].Event.ID != "e1" {
+		t.Errorf("Update() changes = %v, want the new event", changes)
+	}
+
+	s, _ := m.Shipment("dhl", "1")
+	if s.Status != tracker.StatusDelivered || len(s.Events) != 2 || !s.ETA.IsZero() {
+		t.Errorf("Shipment() = %s with %d events and ETA %v, want delivered with 2 and no ETA", s.Status, len(s.Events), s.ETA)
+	}
+
+	// The caller's update isn't normalized in place.
+	if update.Status != tracker.StatusUnknown || update.Events[0].ID != "e1" {
+		t.Errorf("Update() modified the update: %s %v", update.Status, update.Events)
+	}
+}
+
+func TestMonitorDedupByID(t *testing.T) {
+	now := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	m := tracker.NewMonitor()
+
+	first := tracker.Event{ID: "e1", Status: tracker.StatusInTransit, Time: now}
+	if _, err := m.Update(&tracker.Shipment{Courier: "dhl", TrackingNumber: "1", Events: []tracker.Event{first}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The courier corrected the time of a known event.
+	moved := first
+	moved.Time = now.Add(time.Minute)
+	changes, err := m.Update(&tracker.Shipment{Courier: "dhl", TrackingNumber: "1", Events: []tracker.Event{moved}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Update() changes = %v, want none", changes)
+	}
+}
+
+func TestMonitorRefresh(t *testing.T) {
+	fake := tracker.NewFake("ups")
+	fake.Set("1Z5R89390357567127", tracker.Shipment{Status: tracker.StatusInTransit})
+
+	m := tracker.NewMonitor()
+	changes, err := m.Refresh(context.Background(), tracker.NewRegistry(fake), parcel.Tracking{
+		Courier:        "ups",
+		TrackingNumber: "1Z5R89390357567127",
+	})
+	if err != nil {
+		t.Fatalf("Refresh() error %v", err)
+	}
+	if len(changes) != 1 || changes[0].To != tracker.StatusInTransit {
+		t.Errorf("Refresh() changes = %v", changes)
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to tracker.Status
+		want     bool
+	}{
+		{tracker.StatusUnknown, tracker.StatusDelivered, true},
+		{tracker.StatusInTransit, tracker.StatusLabelCreated, false},
+		{tracker.StatusOutForDelivery, tracker.StatusInTransit, true},
+		{tracker.StatusDelivered, tracker.StatusInTransit, false},
+		{tracker.StatusReturned, tracker.StatusReturned, true},
+		{tracker.StatusReturned, tracker.StatusDelivered, false},
+	}
+
+	for _, tt := range tests {
+		if got := tracker.CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestMonitorRestore(t *testing.T) {
+	now := time.Date(2023, 6, 1, 9, 0, 0, 0, time.UTC)
+	stored := tracker.Shipment{
+		Courier:        "ups",
+		TrackingNumber: "1Z1",
+		Status:         tracker.StatusInTransit,
+		Events:         []tracker.Event{{Status: tracker.StatusInTransit, Code: "I", Time: now}},
+	}
+
+	m := tracker.NewMonitor()
+	m.Restore(stored)
+
+	changes, err := m.Update(&tracker.Shipment{
+		Courier:        "ups",
+		TrackingNumber: "1Z1",
+		Events:         stored.Events,
+	})
+	if err != nil {
+		t.Fatalf("Update() error %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("Update() changes = %v, want none after Restore()", changes)
+	}
+}
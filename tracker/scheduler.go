@@ -0,0 +1,463 @@
+package tracker
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+// Clock abstracts time so the scheduler can be driven by tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// DefaultIntervals are the polling intervals used for each status when none
+// are configured.
+var DefaultIntervals = map[Status]time.Duration{
+	StatusUnknown:        2 * time.Hour,
+	StatusLabelCreated:   6 * time.Hour,
+	StatusInTransit:      3 * time.Hour,
+	StatusOutForDelivery: 20 * time.Minute,
+	StatusException:      time.Hour,
+	StatusDelivered:      12 * time.Hour,
+	StatusReturned:       12 * time.Hour,
+}
+
+// Rate is a token bucket rate limit.
+type Rate struct {
+	// PerSecond is the sustained request rate.
+	PerSecond float64
+
+	// Burst is the number of requests allowed at once.
+	Burst int
+}
+
+// SchedulerConfig configures a Scheduler. Only Registry is required.
+type SchedulerConfig struct {
+	Registry *Registry
+
+	// Monitor receives every fetched shipment. A new monitor is used if nil.
+	Monitor *Monitor
+
+	// Clock defaults to the system clock.
+	Clock Clock
+
+	// Intervals overrides DefaultIntervals per status.
+	Intervals map[Status]time.Duration
+
+	// StaleLabel is how long a shipment may stay label created before it is
+	// polled at StaleLabelInterval. Defaults to 72 hours and 24 hours.
+	StaleLabel         time.Duration
+	StaleLabelInterval time.Duration
+
+	// Grace is how long delivered and returned shipments are still polled,
+	// in case of late events. Defaults to 24 hours.
+	Grace time.Duration
+
+	// RateLimits limits the requests per courier code. Couriers without a
+	// rate limit are unlimited.
+	RateLimits map[string]Rate
+
+	// MinBackoff and MaxBackoff bound the exponential backoff after
+	// temporary courier errors. Default to one minute and one hour.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// Workers is the number of concurrent requests. Defaults to 8.
+	Workers int
+}
+
+// Scheduler polls tracked shipments at intervals depending on their status.
+type Scheduler struct {
+	cfg     SchedulerConfig
+	monitor *Monitor
+	clock   Clock
+
+	mu       sync.Mutex
+	queue    entryQueue
+	entries  map[string]*entry
+	buckets  map[string]*bucket
+	paused   map[string]time.Time // courier backoff after 429s
+	failures map[string]int       // consecutive 429s per courier
+	wake     chan struct{}
+}
+
+// entry is a single tracked shipment.
+type entry struct {
+	tracking parcel.Tracking
+	next     time.Time
+	status   Status
+	since    time.Time // when the status was first seen
+	failures int
+	index    int
+}
+
+// NewScheduler returns a scheduler with no tracked shipments.
+func NewScheduler(cfg SchedulerConfig) *Scheduler {
+	if cfg.Monitor == nil {
+		cfg.Monitor = NewMonitor()
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = systemClock{}
+	}
+	if cfg.StaleLabel == 0 {
+		cfg.StaleLabel = 72 * time.Hour
+	}
+	if cfg.StaleLabelInterval == 0 {
+		cfg.StaleLabelInterval = 24 * time.Hour
+	}
+	if cfg.Grace == 0 {
+		cfg.Grace = 24 * time.Hour
+	}
+	if cfg.MinBackoff == 0 {
+		cfg.MinBackoff = time.Minute
+	}
+	if cfg.MaxBackoff == 0 {
+		cfg.MaxBackoff = time.Hour
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 8
+	}
+
+	return &Scheduler{
+		cfg:      cfg,
+		monitor:  cfg.Monitor,
+		clock:    cfg.Clock,
+		entries:  make(map[string]*entry),
+		buckets:  make(map[string]*bucket),
+		paused:   make(map[string]time.Time),
+		failures: make(map[string]int),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Monitor returns the monitor updated by the scheduler.
+func (s *Scheduler) Monitor() *Monitor {
+	return s.monitor
+}
+
+// Add starts tracking a shipment. It is polled on the next tick.
+func (s *Scheduler) Add(t parcel.Tracking) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := shipmentKey(t.Courier, t.TrackingNumber)
+	if _, ok := s.entries[key]; ok {
+		return
+	}
+
+	now := s.clock.Now()
+	e := &entry{tracking: t, next: now, since: now}
+	s.entries[key] = e
+	heap.Push(&s.queue, e)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Remove stops tracking a shipment.
+func (s *Scheduler) Remove(courier, trackingNumber string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.remove(shipmentKey(courier, trackingNumber))
+}
+
+func (s *Scheduler) remove(key string) {
+	e, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+	if e.index >= 0 {
+		heap.Remove(&s.queue, e.index)
+	}
+}
+
+// Len returns the number of tracked shipments.
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// NextPoll returns when a shipment is next polled.
+func (s *Scheduler) NextPoll(courier, trackingNumber string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[shipmentKey(courier, trackingNumber)]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return e.next, true
+}
+
+// Run polls shipments as they become due until the context is cancelled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		s.Tick(ctx)
+
+		wait := time.Hour
+		s.mu.Lock()
+		if len(s.queue) > 0 {
+			wait = s.queue[0].next.Sub(s.clock.Now())
+		}
+		s.mu.Unlock()
+		if wait < 0 {
+			wait = 0
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.wake:
+		case <-s.clock.After(wait):
+		}
+	}
+}
+
+// Tick polls every shipment that is due and allowed by the rate limits.
+// It returns the number of shipments polled.
+func (s *Scheduler) Tick(ctx context.Context) int {
+	due := s.due()
+	if len(due) == 0 {
+		return 0
+	}
+
+	jobs := make(chan *entry)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < s.cfg.Workers && i < len(due); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				s.poll(ctx, e)
+			}
+		}()
+	}
+
+	for _, e := range due {
+		jobs <- e
+	}
+	close(jobs)
+	wg.Wait()
+
+	return len(due)
+}
+
+// due removes the entries that can be polled now from the queue. Entries
+// held back by a rate limit or courier backoff are rescheduled.
+func (s *Scheduler) due() []*entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	out := make([]*entry, 0)
+	deferred := make([]*entry, 0)
+
+	for len(s.queue) > 0 && !s.queue[0].next.After(now) {
+		e := heap.Pop(&s.queue).(*entry)
+		courier := e.tracking.Courier
+
+		if until, ok := s.paused[courier]; ok && now.Before(until) {
+			e.next = until
+			deferred = append(deferred, e)
+			continue
+		}
+
+		if b := s.bucket(courier); b != nil {
+			if wait := b.take(now); wait > 0 {
+				e.next = now.Add(wait)
+				deferred = append(deferred, e)
+				continue
+			}
+		}
+
+		out = append(out, e)
+	}
+
+	for _, e := range deferred {
+		heap.Push(&s.queue, e)
+	}
+
+	return out
+}
+
+// poll fetches a single shipment and reschedules it.
+func (s *Scheduler) poll(ctx context.Context, e *entry) {
+	_, err := s.monitor.Refresh(ctx, s.cfg.Registry, e.tracking)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := shipmentKey(e.tracking.Courier, e.tracking.TrackingNumber)
+	if s.entries[key] != e {
+		// Removed while polling.
+		return
+	}
+
+	now := s.clock.Now()
+	courier := e.tracking.Courier
+
+	var apiErr *APIError
+	switch {
+	case err == nil, errors.Is(err, ErrTransition), errors.Is(err, ErrNotFound):
+		e.failures = 0
+		delete(s.failures, courier)
+	case errors.Is(err, ErrInvalid), errors.Is(err, ErrNoProvider):
+		s.remove(key)
+		return
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests:
+		s.failures[courier]++
+		s.paused[courier] = now.Add(s.backoff(s.failures[courier]))
+		e.next = s.paused[courier]
+		heap.Push(&s.queue, e)
+		return
+	default:
+		e.failures++
+		e.next = now.Add(s.backoff(e.failures))
+		heap.Push(&s.queue, e)
+		return
+	}
+
+	if sh, ok := s.monitor.Shipment(courier, e.tracking.TrackingNumber); ok && sh.Status != e.status {
+		e.status = sh.Status
+		e.since = now
+	}
+
+	if e.status == StatusDelivered || e.status == StatusReturned {
+		if now.Sub(e.since) >= s.cfg.Grace {
+			s.remove(key)
+			return
+		}
+	}
+
+	e.next = now.Add(s.interval(e, now))
+	heap.Push(&s.queue, e)
+}
+
+// interval returns the polling interval for an entry's status.
+func (s *Scheduler) interval(e *entry, now time.Time) time.Duration {
+	if e.status == StatusLabelCreated && now.Sub(e.since) >= s.cfg.StaleLabel {
+		return s.cfg.StaleLabelInterval
+	}
+
+	d, ok := s.cfg.Intervals[e.status]
+	if !ok {
+		d = DefaultIntervals[e.status]
+	}
+
+	if e.status == StatusDelivered || e.status == StatusReturned {
+		// Poll once more at the end of the grace period.
+		if left := s.cfg.Grace - now.Sub(e.since); left < d {
+			d = left
+		}
+	}
+
+	return d
+}
+
+// backoff returns the exponential backoff after n consecutive failures.
+func (s *Scheduler) backoff(n int) time.Duration {
+	d := s.cfg.MinBackoff
+	for i := 1; i < n && d < s.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > s.cfg.MaxBackoff {
+		d = s.cfg.MaxBackoff
+	}
+
+	return d
+}
+
+// bucket returns the rate limiter for a courier, or nil if unlimited.
+func (s *Scheduler) bucket(courier string) *bucket {
+	if b, ok := s.buckets[courier]; ok {
+		return b
+	}
+
+	r, ok := s.cfg.RateLimits[courier]
+	if !ok || r.PerSecond <= 0 {
+		return nil
+	}
+	burst := r.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	b := &bucket{rate: r.PerSecond, burst: float64(burst), tokens: float64(burst), last: s.clock.Now()}
+	s.buckets[courier] = b
+
+	return b
+}
+
+// bucket is a token bucket driven by the scheduler clock.
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token if one is available. Otherwise it returns how long
+// until the next token.
+func (b *bucket) take(now time.Time) time.Duration {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// entryQueue is a min-heap of entries ordered by their next poll.
+type entryQueue []*entry
+
+func (q entryQueue) Len() int           { return len(q) }
+func (q entryQueue) Less(i, j int) bool { return q[i].next.Before(q[j].next) }
+
+func (q entryQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *entryQueue) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *entryQueue) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	e.index = -1
+	*q = old[:n-1]
+
+	return e
+}
@@ -0,0 +1,190 @@
+package tracker_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+)
+
+// testClock is a manually advanced clock.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) After(d time.Duration) <-chan time.Time {
+	return make(chan time.Time)
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newTestScheduler(cfg tracker.SchedulerConfig) (*tracker.Scheduler, *testClock) {
+	clock := &testClock{now: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)}
+	cfg.Clock = clock
+
+	return tracker.NewScheduler(cfg), clock
+}
+
+func TestSchedulerIntervals(t *testing.T) {
+	fake := tracker.NewFake("fedex")
+	s, clock := newTestScheduler(tracker.SchedulerConfig{Registry: tracker.NewRegistry(fake)})
+	ctx := context.Background()
+	num := "986578788855"
+	s.Add(parcel.Tracking{Courier: "fedex", TrackingNumber: num})
+
+	steps := []struct {
+		status  tracker.Status
+		advance time.Duration
+		next    time.Duration
+	}{
+		{status: tracker.StatusInTransit, next: 3 * time.Hour},
+		{status: tracker.StatusOutForDelivery, advance: 3 * time.Hour, next: 20 * time.Minute},
+		{status: tracker.StatusDelivered, advance: 20 * time.Minute, next: 12 * time.Hour},
+		{status: tracker.StatusDelivered, advance: 12 * time.Hour, next: 12 * time.Hour},
+	}
+
+	for i, step := range steps {
+		clock.Advance(step.advance)
+		fake.Set(num, tracker.Shipment{Status: step.status})
+		if n := s.Tick(ctx); n != 1 {
+			t.Fatalf("step %d: Tick() polled %d, want 1", i, n)
+		}
+		next, ok := s.NextPoll("fedex", num)
+		if !ok {
+			t.Fatalf("step %d: NextPoll() shipment not tracked", i)
+		}
+		if want := clock.Now().Add(step.next); !next.Equal(want) {
+			t.Errorf("step %d: NextPoll() = %v, want %v", i, next, want)
+		}
+	}
+
+	// Not yet due.
+	if n := s.Tick(ctx); n != 0 {
+		t.Errorf("Tick() polled %d before due", n)
+	}
+
+	// The grace period after delivery has passed.
+	clock.Advance(12 * time.Hour)
+	s.Tick(ctx)
+	if s.Len() != 0 {
+		t.Errorf("Len() = %d, want delivered shipment removed after grace period", s.Len())
+	}
+	if got := fake.Calls(num); got != 5 {
+		t.Errorf("provider called %d times, want 5", got)
+	}
+}
+
+func TestSchedulerStaleLabel(t *testing.T) {
+	fake := tracker.NewFake("ups")
+	s, clock := newTestScheduler(tracker.SchedulerConfig{Registry: tracker.NewRegistry(fake)})
+	ctx := context.Background()
+	num := "1Z5R89390357567127"
+	fake.Set(num, tracker.Shipment{Status: tracker.StatusLabelCreated})
+	s.Add(parcel.Tracking{Courier: "ups", TrackingNumber: num})
+
+	for clock.Now().Before(time.Date(2023, 6, 4, 0, 0, 0, 0, time.UTC)) {
+		s.Tick(ctx)
+		next, _ := s.NextPoll("ups", num)
+		clock.Advance(next.Sub(clock.Now()))
+	}
+
+	s.Tick(ctx)
+	next, _ := s.NextPoll("ups", num)
+	if d := next.Sub(clock.Now()); d != 24*time.Hour {
+		t.Errorf("stale label interval = %v, want 24h", d)
+	}
+}
+
+func TestSchedulerRateLimit(t *testing.T) {
+	fake := tracker.NewFake("ups")
+	s, clock := newTestScheduler(tracker.SchedulerConfig{
+		Registry:   tracker.NewRegistry(fake),
+		RateLimits: map[string]tracker.Rate{"ups": {PerSecond: 1, Burst: 2}},
+	})
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		num := fmt.Sprintf("1Z%d", i)
+		fake.Set(num, tracker.Shipment{Status: tracker.StatusInTransit})
+		s.Add(parcel.Tracking{Courier: "ups", TrackingNumber: num})
+	}
+
+	want := []int{2, 1, 2}
+	advance := []time.Duration{0, time.Second, 2 * time.Second}
+	for i := range want {
+		clock.Advance(advance[i])
+		if n := s.Tick(ctx); n != want[i] {
+			t.Errorf("tick %d polled %d, want %d", i, n, want[i])
+		}
+	}
+}
+
+func TestSchedulerBackoff(t *testing.T) {
+	fake := tracker.NewFake("dhl")
+	s, clock := newTestScheduler(tracker.SchedulerConfig{Registry: tracker.NewRegistry(fake)})
+	ctx := context.Background()
+
+	fake.SetError("limited", &tracker.APIError{Courier: "dhl", StatusCode: http.StatusTooManyRequests})
+	fake.SetError("broken", &tracker.APIError{Courier: "dhl", StatusCode: http.StatusBadGateway})
+	fake.SetError("invalid", &tracker.APIError{Courier: "dhl", StatusCode: http.StatusBadRequest, Err: tracker.ErrInvalid})
+	for _, num := range []string{"limited", "broken", "invalid"} {
+		s.Add(parcel.Tracking{Courier: "dhl", TrackingNumber: num})
+	}
+
+	if n := s.Tick(ctx); n != 3 {
+		t.Fatalf("Tick() polled %d, want 3", n)
+	}
+	if _, ok := s.NextPoll("dhl", "invalid"); ok {
+		t.Error("invalid tracking number should no longer be tracked")
+	}
+	for _, num := range []string{"limited", "broken"} {
+		next, _ := s.NextPoll("dhl", num)
+		if d := next.Sub(clock.Now()); d != time.Minute {
+			t.Errorf("%s backoff = %v, want 1m", num, d)
+		}
+	}
+
+	clock.Advance(time.Minute)
+	s.Tick(ctx)
+	for _, num := range []string{"limited", "broken"} {
+		next, _ := s.NextPoll("dhl", num)
+		if d := next.Sub(clock.Now()); d != 2*time.Minute {
+			t.Errorf("%s backoff = %v, want 2m", num, d)
+		}
+	}
+
+	// Rate limited couriers are paused for every shipment.
+	fake.Set("other", tracker.Shipment{Status: tracker.StatusInTransit})
+	s.Add(parcel.Tracking{Courier: "dhl", TrackingNumber: "other"})
+	if n := s.Tick(ctx); n != 0 {
+		t.Errorf("Tick() polled %d while courier is paused", n)
+	}
+
+	fake.Set("limited", tracker.Shipment{Status: tracker.StatusInTransit})
+	fake.Set("broken", tracker.Shipment{Status: tracker.StatusInTransit})
+	clock.Advance(2 * time.Minute)
+	if n := s.Tick(ctx); n != 3 {
+		t.Errorf("Tick() polled %d after backoff, want 3", n)
+	}
+	next, _ := s.NextPoll("dhl", "limited")
+	if d := next.Sub(clock.Now()); d != 3*time.Hour {
+		t.Errorf("interval after recovery = %v, want 3h", d)
+	}
+}
@@ -0,0 +1,63 @@
+package tracker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Status is the normalized state of a shipment or event.
+type Status int
+
+const (
+	StatusUnknown Status = iota
+	StatusLabelCreated
+	StatusInTransit
+	StatusOutForDelivery
+	StatusDelivered
+	StatusException
+	StatusReturned
+)
+
+var statusNames = [...]string{
+	StatusUnknown:        "unknown",
+	StatusLabelCreated:   "label_created",
+	StatusInTransit:      "in_transit",
+	StatusOutForDelivery: "out_for_delivery",
+	StatusDelivered:      "delivered",
+	StatusException:      "exception",
+	StatusReturned:       "returned",
+}
+
+func (s Status) String() string {
+	if s < 0 || int(s) >= len(statusNames) {
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+
+	return statusNames[s]
+}
+
+// ParseStatus returns the status for a name returned by Status.String.
+func ParseStatus(name string) (Status, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, v := range statusNames {
+		if v == name {
+			return Status(i), nil
+		}
+	}
+
+	return StatusUnknown, fmt.Errorf("unknown status %q", name)
+}
+
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Status) UnmarshalText(buf []byte) error {
+	v, err := ParseStatus(string(buf))
+	if err != nil {
+		return err
+	}
+	*s = v
+
+	return nil
+}
@@ -0,0 +1,124 @@
+// Package bolt implements a store.Store in a single bbolt database file.
+package bolt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bbolt "go.etcd.io/bbolt"
+
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/store"
+)
+
+// bucket holds every shipment, encoded as JSON, keyed by store.Key.
+var bucket = []byte("shipments")
+
+// Store is a store.Store backed by a bbolt file.
+type Store struct {
+	db *bbolt.DB
+}
+
+// Open opens or creates the database file at path.
+func Open(path string) (*Store, error) {
+	db, err := bbolt.Open(path, 0o600, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Put(ctx context.Context, sh *tracker.Shipment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(sh)
+	if err != nil {
+		return fmt.Errorf("bolt: encoding shipment: %w", err)
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(store.Key(sh.Courier, sh.TrackingNumber)), buf)
+	})
+}
+
+func (s *Store) Get(ctx context.Context, courier, trackingNumber string) (*tracker.Shipment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var out *tracker.Shipment
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		buf := tx.Bucket(bucket).Get([]byte(store.Key(courier, trackingNumber)))
+		if buf == nil {
+			return store.ErrNotFound
+		}
+		var err error
+		out, err = decode(buf)
+		return err
+	})
+
+	return out, err
+}
+
+func (s *Store) Delete(ctx context.Context, courier, trackingNumber string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucket).Delete([]byte(store.Key(courier, trackingNumber)))
+	})
+}
+
+func (s *Store) List(ctx context.Context, statuses ...tracker.Status) ([]*tracker.Shipment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]*tracker.Shipment, 0)
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(k, v []byte) error {
+			sh, err := decode(v)
+			if err != nil {
+				return err
+			}
+			if store.Matches(sh.Status, statuses) {
+				out = append(out, sh)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	store.Sort(out)
+
+	return out, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func decode(buf []byte) (*tracker.Shipment, error) {
+	var out tracker.Shipment
+	if err := json.Unmarshal(buf, &out); err != nil {
+		return nil, fmt.Errorf("bolt: decoding shipment: %w", err)
+	}
+
+	return &out, nil
+}
@@ -0,0 +1,51 @@
+package bolt_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/store"
+	"dev.freespoke.com/go-package-tracking/tracker/store/bolt"
+	"dev.freespoke.com/go-package-tracking/tracker/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := bolt.Open(filepath.Join(t.TempDir(), "shipments.db"))
+		if err != nil {
+			t.Fatalf("bolt.Open() error %v", err)
+		}
+		return s
+	})
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shipments.db")
+	s, err := bolt.Open(path)
+	if err != nil {
+		t.Fatalf("bolt.Open() error %v", err)
+	}
+	err = s.Put(context.Background(), &tracker.Shipment{Courier: "ups", TrackingNumber: "1Z1", Status: tracker.StatusDelivered})
+	if err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error %v", err)
+	}
+
+	s, err = bolt.Open(path)
+	if err != nil {
+		t.Fatalf("bolt.Open() error %v", err)
+	}
+	defer s.Close()
+
+	got, err := s.Get(context.Background(), "ups", "1Z1")
+	if err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	if got.Status != tracker.StatusDelivered {
+		t.Errorf("Get() status = %s, want delivered", got.Status)
+	}
+}
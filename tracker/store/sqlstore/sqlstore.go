@@ -0,0 +1,296 @@
+// Package sqlstore implements a store.Store on database/sql.
+//
+// The schema is created and upgraded by Migrate, which New runs. Any driver
+// for SQLite, PostgreSQL or MySQL can be used; the dialect only selects the
+// query placeholder style.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/store"
+)
+
+// Dialect selects the SQL placeholder style.
+type Dialect int
+
+const (
+	SQLite Dialect = iota
+	MySQL
+	Postgres
+)
+
+// migrations are applied in order. Existing migrations must never change;
+// add new ones to the end.
+var migrations = []string{
+	`CREATE TABLE shipments (
+		courier VARCHAR(64) NOT NULL,
+		tracking_number VARCHAR(128) NOT NULL,
+		status VARCHAR(32) NOT NULL,
+		eta BIGINT,
+		updated_at BIGINT NOT NULL,
+		PRIMARY KEY (courier, tracking_number)
+	)`,
+	`CREATE INDEX shipments_status ON shipments (status)`,
+	`CREATE TABLE events (
+		courier VARCHAR(64) NOT NULL,
+		tracking_number VARCHAR(128) NOT NULL,
+		seq INTEGER NOT NULL,
+		event_id VARCHAR(128) NOT NULL,
+		status VARCHAR(32) NOT NULL,
+		code VARCHAR(64) NOT NULL,
+		description TEXT NOT NULL,
+		city VARCHAR(128) NOT NULL,
+		state VARCHAR(64) NOT NULL,
+		postal_code VARCHAR(32) NOT NULL,
+		country VARCHAR(64) NOT NULL,
+		event_time BIGINT,
+		PRIMARY KEY (courier, tracking_number, seq)
+	)`,
+}
+
+// Config configures a Store.
+type Config struct {
+	Dialect Dialect
+}
+
+// Store is a store.Store backed by a SQL database.
+type Store struct {
+	db  *sql.DB
+	cfg Config
+}
+
+// New returns a store using db, migrating the schema to the latest version.
+// Closing the store doesn't close db.
+func New(ctx context.Context, db *sql.DB, cfg Config) (*Store, error) {
+	s := &Store{db: db, cfg: cfg}
+	if err := s.Migrate(ctx); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Migrate applies any migrations not yet recorded in schema_migrations.
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL PRIMARY KEY)`)
+	if err != nil {
+		return fmt.Errorf("sqlstore: creating schema_migrations: %w", err)
+	}
+
+	var current sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("sqlstore: reading schema version: %w", err)
+	}
+
+	for v := int(current.Int64) + 1; v <= len(migrations); v++ {
+		err := s.tx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migrations[v-1]); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, s.q(`INSERT INTO schema_migrations (version) VALUES (?)`), v)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("sqlstore: migration %d: %w", v, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) Put(ctx context.Context, sh *tracker.Shipment) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		var exists int
+		err := tx.QueryRowContext(ctx, s.q(`SELECT 1 FROM shipments WHERE courier = ? AND tracking_number = ?`), sh.Courier, sh.TrackingNumber).Scan(&exists)
+		switch {
+		case err == sql.ErrNoRows:
+			_, err = tx.ExecContext(ctx, s.q(`INSERT INTO shipments (courier, tracking_number, status, eta, updated_at) VALUES (?, ?, ?, ?, ?)`),
+				sh.Courier, sh.TrackingNumber, sh.Status.String(), nanos(sh.ETA), time.Now().UnixNano())
+		case err == nil:
+			_, err = tx.ExecContext(ctx, s.q(`UPDATE shipments SET status = ?, eta = ?, updated_at = ? WHERE courier = ? AND tracking_number = ?`),
+				sh.Status.String(), nanos(sh.ETA), time.Now().UnixNano(), sh.Courier, sh.TrackingNumber)
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, s.q(`DELETE FROM events WHERE courier = ? AND tracking_number = ?`), sh.Courier, sh.TrackingNumber); err != nil {
+			return err
+		}
+
+		insert := s.q(`INSERT INTO events (courier, tracking_number, seq, event_id, status, code, description, city, state, postal_code, country, event_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+		for i, e := range sh.Events {
+			l := e.Location
+			_, err := tx.ExecContext(ctx, insert, sh.Courier, sh.TrackingNumber, i, e.ID, e.Status.String(), e.Code, e.Description, l.City, l.State, l.PostalCode, l.Country, nanos(e.Time))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (s *Store) Get(ctx context.Context, courier, trackingNumber string) (*tracker.Shipment, error) {
+	out, err := s.query(ctx, `s.courier = ? AND s.tracking_number = ?`, courier, trackingNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, store.ErrNotFound
+	}
+
+	return out[0], nil
+}
+
+func (s *Store) Delete(ctx context.Context, courier, trackingNumber string) error {
+	return s.tx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, s.q(`DELETE FROM events WHERE courier = ? AND tracking_number = ?`), courier, trackingNumber); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, s.q(`DELETE FROM shipments WHERE courier = ? AND tracking_number = ?`), courier, trackingNumber)
+		return err
+	})
+}
+
+func (s *Store) List(ctx context.Context, statuses ...tracker.Status) ([]*tracker.Shipment, error) {
+	if len(statuses) == 0 {
+		return s.query(ctx, `1 = 1`)
+	}
+
+	args := make([]any, len(statuses))
+	for i, v := range statuses {
+		args[i] = v.String()
+	}
+	in := strings.TrimSuffix(strings.Repeat("?, ", len(statuses)), ", ")
+
+	return s.query(ctx, `s.status IN (`+in+`)`, args...)
+}
+
+// Close does nothing; the database is owned by the caller.
+func (s *Store) Close() error {
+	return nil
+}
+
+// query returns the shipments matching a condition on the shipments table,
+// aliased s, with their events.
+func (s *Store) query(ctx context.Context, where string, args ...any) ([]*tracker.Shipment, error) {
+	rows, err := s.db.QueryContext(ctx, s.q(`SELECT s.courier, s.tracking_number, s.status, s.eta FROM shipments s WHERE `+where+` ORDER BY s.courier, s.tracking_number`), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]*tracker.Shipment, 0)
+	byKey := make(map[string]*tracker.Shipment)
+	for rows.Next() {
+		var (
+			sh     tracker.Shipment
+			status string
+			eta    sql.NullInt64
+		)
+		if err := rows.Scan(&sh.Courier, &sh.TrackingNumber, &status, &eta); err != nil {
+			return nil, err
+		}
+		if sh.Status, err = tracker.ParseStatus(status); err != nil {
+			return nil, err
+		}
+		sh.ETA = fromNanos(eta)
+		sh.Events = make([]tracker.Event, 0)
+		out = append(out, &sh)
+		byKey[store.Key(sh.Courier, sh.TrackingNumber)] = &sh
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return out, nil
+	}
+
+	events, err := s.db.QueryContext(ctx, s.q(`SELECT e.courier, e.tracking_number, e.event_id, e.status, e.code, e.description, e.city, e.state, e.postal_code, e.country, e.event_time
+		FROM events e JOIN shipments s ON s.courier = e.courier AND s.tracking_number = e.tracking_number
+		WHERE `+where+` ORDER BY e.courier, e.tracking_number, e.seq`), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer events.Close()
+
+	for events.Next() {
+		var (
+			courier, num, status string
+			e                    tracker.Event
+			ts                   sql.NullInt64
+		)
+		l := &e.Location
+		if err := events.Scan(&courier, &num, &e.ID, &status, &e.Code, &e.Description, &l.City, &l.State, &l.PostalCode, &l.Country, &ts); err != nil {
+			return nil, err
+		}
+		if e.Status, err = tracker.ParseStatus(status); err != nil {
+			return nil, err
+		}
+		e.Time = fromNanos(ts)
+		if sh, ok := byKey[store.Key(courier, num)]; ok {
+			sh.Events = append(sh.Events, e)
+		}
+	}
+
+	return out, events.Err()
+}
+
+// tx runs fn in a transaction, committing if it returns no error.
+func (s *Store) tx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// q rewrites ? placeholders for the dialect.
+func (s *Store) q(query string) string {
+	if s.cfg.Dialect != Postgres {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// nanos stores times as UTC nanoseconds. The zero time is stored as NULL.
+func nanos(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UnixNano()
+}
+
+func fromNanos(n sql.NullInt64) time.Time {
+	if !n.Valid {
+		return time.Time{}
+	}
+
+	return time.Unix(0, n.Int64).UTC()
+}
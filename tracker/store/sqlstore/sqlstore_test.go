@@ -0,0 +1,57 @@
+package sqlstore_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"dev.freespoke.com/go-package-tracking/tracker/store"
+	"dev.freespoke.com/go-package-tracking/tracker/store/sqlstore"
+	"dev.freespoke.com/go-package-tracking/tracker/store/storetest"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "shipments.sqlite"))
+	if err != nil {
+		t.Fatalf("sql.Open() error %v", err)
+	}
+	// SQLite allows a single writer.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := sqlstore.New(context.Background(), openDB(t), sqlstore.Config{Dialect: sqlstore.SQLite})
+		if err != nil {
+			t.Fatalf("sqlstore.New() error %v", err)
+		}
+		return s
+	})
+}
+
+func TestMigrateTwice(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	s, err := sqlstore.New(ctx, db, sqlstore.Config{})
+	if err != nil {
+		t.Fatalf("sqlstore.New() error %v", err)
+	}
+	if err := s.Migrate(ctx); err != nil {
+		t.Fatalf("Migrate() second run error %v", err)
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != 3 {
+		t.Errorf("schema version = %d, want 3", version)
+	}
+}
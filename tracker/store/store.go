@@ -0,0 +1,156 @@
+// Package store persists tracked shipments and their event history.
+//
+// Implementations are provided for memory (this package), a single bbolt
+// file (store/bolt) and database/sql (store/sqlstore). They all pass the
+// conformance tests in store/storetest.
+package store
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"dev.freespoke.com/go-package-tracking/tracker"
+)
+
+var ErrNotFound = errors.New("shipment not found")
+
+// Store saves shipments keyed by courier code and tracking number.
+type Store interface {
+	// Put saves a shipment with its status, ETA and events, replacing any
+	// shipment stored for the same courier and tracking number.
+	Put(ctx context.Context, s *tracker.Shipment) error
+
+	// Get returns a stored shipment, or ErrNotFound.
+	Get(ctx context.Context, courier, trackingNumber string) (*tracker.Shipment, error)
+
+	// Delete removes a shipment and its events. Deleting a shipment that
+	// isn't stored is not an error.
+	Delete(ctx context.Context, courier, trackingNumber string) error
+
+	// List returns the stored shipments with any of the given statuses, or
+	// all shipments if none are given. Shipments are ordered by courier and
+	// tracking number.
+	List(ctx context.Context, statuses ...tracker.Status) ([]*tracker.Shipment, error)
+
+	Close() error
+}
+
+// Memory is an in-memory Store.
+type Memory struct {
+	mu        sync.RWMutex
+	shipments map[string]tracker.Shipment
+}
+
+// NewMemory returns an empty in-memory store.
+func NewMemory() *Memory {
+	return &Memory{
+		shipments: make(map[string]tracker.Shipment),
+	}
+}
+
+func (m *Memory) Put(ctx context.Context, s *tracker.Shipment) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.shipments[Key(s.Courier, s.TrackingNumber)] = clone(s)
+
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, courier, trackingNumber string) (*tracker.Shipment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.shipments[Key(courier, trackingNumber)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := clone(&s)
+
+	return &out, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, courier, trackingNumber string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.shipments, Key(courier, trackingNumber))
+
+	return nil
+}
+
+func (m *Memory) List(ctx context.Context, statuses ...tracker.Status) ([]*tracker.Shipment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	out := make([]*tracker.Shipment, 0)
+	for _, s := range m.shipments {
+		if !Matches(s.Status, statuses) {
+			continue
+		}
+		c := clone(&s)
+		out = append(out, &c)
+	}
+	Sort(out)
+
+	return out, nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+// Key returns the key of a shipment, used by implementations that need a
+// single string key.
+func Key(courier, trackingNumber string) string {
+	return courier + "\x00" + trackingNumber
+}
+
+// Matches reports whether status is one of statuses, or statuses is empty.
+func Matches(status tracker.Status, statuses []tracker.Status) bool {
+	if len(statuses) == 0 {
+		return true
+	}
+	for _, v := range statuses {
+		if v == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sort orders shipments by courier and tracking number.
+func Sort(s []*tracker.Shipment) {
+	sort.Slice(s, func(i, j int) bool {
+		if s[i].Courier != s[j].Courier {
+			return s[i].Courier < s[j].Courier
+		}
+		return s[i].TrackingNumber < s[j].TrackingNumber
+	})
+}
+
+func clone(s *tracker.Shipment) tracker.Shipment {
+	out := *s
+	out.Events = append([]tracker.Event(nil), s.Events...)
+
+	return out
+}
@@ -0,0 +1,296 @@
+// Package storetest contains the conformance tests every store.Store
+// implementation must pass.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/store"
+)
+
+// Run runs the conformance tests. newStore must return a new, empty store
+// for every call.
+func Run(t *testing.T, newStore func(t *testing.T) store.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s store.Store)
+	}{
+		{"GetMissing", testGetMissing},
+		{"RoundTrip", testRoundTrip},
+		{"Replace", testReplace},
+		{"CourierKey", testCourierKey},
+		{"List", testList},
+		{"Delete", testDelete},
+		{"Copies", testCopies},
+		{"Concurrent", testConcurrent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newStore(t)
+			defer s.Close()
+			tt.fn(t, s)
+		})
+	}
+}
+
+var base = time.Date(2023, 6, 1, 9, 30, 0, 123000000, time.UTC)
+
+func shipment(courier, num string, status tracker.Status) *tracker.Shipment {
+	return &tracker.Shipment{
+		Courier:        courier,
+		TrackingNumber: num,
+		Status:         status,
+		ETA:            base.Add(72 * time.Hour),
+		Events: []tracker.Event{
+			{
+				ID:          "e1",
+				Status:      tracker.StatusLabelCreated,
+				Code:        "OC",
+				Description: "Shipment information sent",
+				Location:    tracker.Location{Country: "US"},
+				Time:        base,
+			},
+			{
+				Status:      status,
+				Code:        "IT",
+				Description: "In transit",
+				Location:    tracker.Location{City: "MEMPHIS", State: "TN", PostalCode: "38118", Country: "US"},
+				Time:        base.Add(26 * time.Hour).In(time.FixedZone("CDT", -5*3600)),
+			},
+		},
+	}
+}
+
+// equal compares shipments, allowing time zones to differ.
+func equal(t *testing.T, got, want *tracker.Shipment) {
+	t.Helper()
+
+	if got.Courier != want.Courier || got.TrackingNumber != want.TrackingNumber {
+		t.Errorf("identity = %s %s, want %s %s", got.Courier, got.TrackingNumber, want.Courier, want.TrackingNumber)
+	}
+	if got.Status != want.Status {
+		t.Errorf("status = %s, want %s", got.Status, want.Status)
+	}
+	if !got.ETA.Equal(want.ETA) {
+		t.Errorf("ETA = %v, want %v", got.ETA, want.ETA)
+	}
+	if len(got.Events) != len(want.Events) {
+		t.Fatalf("events = %d, want %d", len(got.Events), len(want.Events))
+	}
+	for i := range want.Events {
+		g, w := got.Events[i], want.Events[i]
+		if !g.Time.Equal(w.Time) {
+			t.Errorf("event %d time = %v, want %v", i, g.Time, w.Time)
+		}
+		g.Time, w.Time = time.Time{}, time.Time{}
+		if g != w {
+			t.Errorf("event %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func testGetMissing(t *testing.T, s store.Store) {
+	_, err := s.Get(context.Background(), "ups", "1Z0")
+	if !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, store.ErrNotFound)
+	}
+}
+
+func testRoundTrip(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	want := shipment("fedex", "986578788855", tracker.StatusInTransit)
+	if err := s.Put(ctx, want); err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+
+	got, err := s.Get(ctx, "fedex", "986578788855")
+	if err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	equal(t, got, want)
+
+	// Zero ETA and no events.
+	empty := &tracker.Shipment{Courier: "dhl", TrackingNumber: "3318810025"}
+	if err := s.Put(ctx, empty); err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+	got, err = s.Get(ctx, "dhl", "3318810025")
+	if err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	if !got.ETA.IsZero() || len(got.Events) != 0 || got.Status != tracker.StatusUnknown {
+		t.Errorf("Get() = %+v, want empty shipment", got)
+	}
+}
+
+func testReplace(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	if err := s.Put(ctx, shipment("ups", "1Z5R89390357567127", tracker.StatusInTransit)); err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+
+	want := shipment("ups", "1Z5R89390357567127", tracker.StatusDelivered)
+	want.ETA = time.Time{}
+	want.Events = append(want.Events[:1], tracker.Event{
+		Status:      tracker.StatusDelivered,
+		Code:        "DL",
+		Description: "Delivered",
+		Time:        base.Add(50 * time.Hour),
+	})
+	if err := s.Put(ctx, want); err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+
+	got, err := s.Get(ctx, "ups", "1Z5R89390357567127")
+	if err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	equal(t, got, want)
+}
+
+func testCourierKey(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	if err := s.Put(ctx, shipment("fedex", "986578788855", tracker.StatusInTransit)); err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+	if err := s.Put(ctx, shipment("dhl", "986578788855", tracker.StatusDelivered)); err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+
+	got, err := s.Get(ctx, "fedex", "986578788855")
+	if err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	if got.Status != tracker.StatusInTransit {
+		t.Errorf("Get() status = %s, shipments with different couriers must be separate", got.Status)
+	}
+}
+
+func testList(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	for _, v := range []*tracker.Shipment{
+		shipment("ups", "1Z2", tracker.StatusDelivered),
+		shipment("ups", "1Z1", tracker.StatusInTransit),
+		shipment("dhl", "9", tracker.StatusOutForDelivery),
+		shipment("fedex", "5", tracker.StatusInTransit),
+	} {
+		if err := s.Put(ctx, v); err != nil {
+			t.Fatalf("Put() error %v", err)
+		}
+	}
+
+	tests := []struct {
+		statuses []tracker.Status
+		want     []string
+	}{
+		{want: []string{"dhl:9", "fedex:5", "ups:1Z1", "ups:1Z2"}},
+		{statuses: []tracker.Status{tracker.StatusInTransit}, want: []string{"fedex:5", "ups:1Z1"}},
+		{statuses: []tracker.Status{tracker.StatusDelivered, tracker.StatusOutForDelivery}, want: []string{"dhl:9", "ups:1Z2"}},
+		{statuses: []tracker.Status{tracker.StatusReturned}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		got, err := s.List(ctx, tt.statuses...)
+		if err != nil {
+			t.Fatalf("List(%v) error %v", tt.statuses, err)
+		}
+		keys := make([]string, 0, len(got))
+		for _, v := range got {
+			keys = append(keys, v.Courier+":"+v.TrackingNumber)
+		}
+		if fmt.Sprint(keys) != fmt.Sprint(tt.want) {
+			t.Errorf("List(%v) = %v, want %v", tt.statuses, keys, tt.want)
+		}
+		for _, v := range got {
+			if len(v.Events) != 2 {
+				t.Errorf("List(%v) %s has %d events, want 2", tt.statuses, v.TrackingNumber, len(v.Events))
+			}
+		}
+	}
+}
+
+func testDelete(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	if err := s.Put(ctx, shipment("ups", "1Z1", tracker.StatusInTransit)); err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+	if err := s.Delete(ctx, "ups", "1Z1"); err != nil {
+		t.Fatalf("Delete() error %v", err)
+	}
+	if _, err := s.Get(ctx, "ups", "1Z1"); !errors.Is(err, store.ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want %v", err, store.ErrNotFound)
+	}
+	if err := s.Delete(ctx, "ups", "1Z1"); err != nil {
+		t.Errorf("Delete() missing shipment error %v", err)
+	}
+
+	// Events of a deleted shipment must not reappear.
+	if err := s.Put(ctx, &tracker.Shipment{Courier: "ups", TrackingNumber: "1Z1"}); err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+	got, err := s.Get(ctx, "ups", "1Z1")
+	if err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	if len(got.Events) != 0 {
+		t.Errorf("Get() events = %d after Delete(), want 0", len(got.Events))
+	}
+}
+
+func testCopies(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	in := shipment("ups", "1Z1", tracker.StatusInTransit)
+	if err := s.Put(ctx, in); err != nil {
+		t.Fatalf("Put() error %v", err)
+	}
+	in.Events[0].Description = "changed"
+	in.Status = tracker.StatusReturned
+
+	got, err := s.Get(ctx, "ups", "1Z1")
+	if err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	got.Events[0].Description = "changed again"
+
+	again, err := s.Get(ctx, "ups", "1Z1")
+	if err != nil {
+		t.Fatalf("Get() error %v", err)
+	}
+	equal(t, again, shipment("ups", "1Z1", tracker.StatusInTransit))
+}
+
+func testConcurrent(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	wg := new(sync.WaitGroup)
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- s.Put(ctx, shipment("usps", fmt.Sprintf("94%02d", i), tracker.StatusInTransit))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Put() error %v", err)
+		}
+	}
+
+	got, err := s.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error %v", err)
+	}
+	if len(got) != 20 {
+		t.Errorf("List() = %d shipments, want 20", len(got))
+	}
+}
@@ -0,0 +1,207 @@
+// Package tracker fetches shipment status from courier APIs and normalizes it
+// into a single event model.
+package tracker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+var (
+	ErrNoProvider = errors.New("no status provider for courier")
+	ErrNotFound   = errors.New("no tracking information available")
+	ErrInvalid    = errors.New("invalid tracking number")
+)
+
+// APIError is returned when a courier API responds with an error.
+type APIError struct {
+	Courier    string
+	StatusCode int    // HTTP status code
+	Code       string // courier error code, if any
+	Message    string
+
+	// Err is a sentinel error such as ErrNotFound, if the error code maps
+	// to one.
+	Err error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: status %d", e.Courier, e.StatusCode)
+	if e.Code != "" {
+		msg += " " + e.Code
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+
+	return msg
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Temporary reports whether the request may succeed if retried later.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// StatusProvider fetches shipment status from a single courier.
+type StatusProvider interface {
+	// Courier returns the courier code handled by the provider. It matches
+	// parcel.Tracking.Courier.
+	Courier() string
+
+	// Status fetches the current status of a shipment.
+	Status(ctx context.Context, t parcel.Tracking) (*Shipment, error)
+}
+
+// Shipment is the normalized status of a single tracking number.
+type Shipment struct {
+	Courier        string `json:"courier"`
+	TrackingNumber string `json:"tracking_number"`
+	Status         Status `json:"status"`
+
+	// Events are ordered oldest first.
+	Events []Event `json:"events"`
+
+	// ETA is the estimated delivery time. It is zero if unknown.
+	ETA time.Time `json:"eta"`
+}
+
+// Event is a single scan or status update reported by the courier.
+type Event struct {
+	// ID is the courier event ID, if the courier provides one.
+	ID          string    `json:"id,omitempty"`
+	Status      Status    `json:"status"`
+	Code        string    `json:"code,omitempty"` // courier specific status code
+	Description string    `json:"description,omitempty"`
+	Location    Location  `json:"location"`
+	Time        time.Time `json:"time"`
+}
+
+// Location is where an event took place. Any field may be empty.
+type Location struct {
+	City       string `json:"city,omitempty"`
+	State      string `json:"state,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+}
+
+// String returns the location as a comma separated list.
+func (l Location) String() string {
+	parts := make([]string, 0, 4)
+	for _, v := range []string{l.City, l.State, l.PostalCode, l.Country} {
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Latest returns the most recent event.
+func (s *Shipment) Latest() (Event, bool) {
+	if len(s.Events) == 0 {
+		return Event{}, false
+	}
+
+	return s.Events[len(s.Events)-1], true
+}
+
+// Normalize orders the events oldest first and sets the shipment status
+// from the latest event if it is unknown.
+func (s *Shipment) Normalize() {
+	sort.SliceStable(s.Events, func(i, j int) bool {
+		return s.Events[i].Time.Before(s.Events[j].Time)
+	})
+
+	if s.Status != StatusUnknown {
+		return
+	}
+	for i := len(s.Events) - 1; i >= 0; i-- {
+		if st := s.Events[i].Status; st != StatusUnknown {
+			s.Status = st
+			return
+		}
+	}
+}
+
+// Registry holds status providers keyed by courier code.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]StatusProvider
+}
+
+// NewRegistry returns a registry with the given providers.
+func NewRegistry(providers ...StatusProvider) *Registry {
+	r := &Registry{
+		providers: make(map[string]StatusProvider),
+	}
+	for _, p := range providers {
+		r.Register(p)
+	}
+
+	return r
+}
+
+// Register adds a provider, replacing any provider for the same courier.
+func (r *Registry) Register(p StatusProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.providers[p.Courier()] = p
+}
+
+// Provider returns the provider for a courier code.
+func (r *Registry) Provider(courier string) (StatusProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.providers[courier]
+	return p, ok
+}
+
+// Couriers returns the registered courier codes in sorted order.
+func (r *Registry) Couriers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]string, 0, len(r.providers))
+	for k := range r.providers {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// Status fetches the status of a tracking result from its courier's provider.
+func (r *Registry) Status(ctx context.Context, t parcel.Tracking) (*Shipment, error) {
+	p, ok := r.Provider(t.Courier)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoProvider, t.Courier)
+	}
+
+	s, err := p.Status(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	if s.Courier == "" {
+		s.Courier = t.Courier
+	}
+	if s.TrackingNumber == "" {
+		s.TrackingNumber = t.TrackingNumber
+	}
+	s.Normalize()
+
+	return s, nil
+}
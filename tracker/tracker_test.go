@@ -0,0 +1,73 @@
+package tracker_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+)
+
+func TestRegistryStatus(t *testing.T) {
+	track, err := parcel.Track("1Z5R89390357567127")
+	if err != nil || len(track) == 0 {
+		t.Fatalf("parcel.Track() = %v, %v", track, err)
+	}
+
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	fake := tracker.NewFake("ups")
+	fake.Set("1Z5R89390357567127", tracker.Shipment{
+		Events: []tracker.Event{
+			{Status: tracker.StatusInTransit, Time: now.Add(time.Hour)},
+			{Status: tracker.StatusLabelCreated, Time: now},
+		},
+	})
+
+	reg := tracker.NewRegistry(fake)
+	got, err := reg.Status(context.Background(), track[0])
+	if err != nil {
+		t.Fatalf("Registry.Status() error %v", err)
+	}
+	if got.Courier != "ups" || got.TrackingNumber != "1Z5R89390357567127" {
+		t.Errorf("Registry.Status() identity = %s %s", got.Courier, got.TrackingNumber)
+	}
+	if got.Status != tracker.StatusInTransit {
+		t.Errorf("Registry.Status() status = %s, want %s", got.Status, tracker.StatusInTransit)
+	}
+	if got.Events[0].Status != tracker.StatusLabelCreated {
+		t.Error("Registry.Status() events should be ordered oldest first")
+	}
+
+	_, err = reg.Status(context.Background(), parcel.Tracking{Courier: "fedex"})
+	if !errors.Is(err, tracker.ErrNoProvider) {
+		t.Errorf("Registry.Status() error = %v, want %v", err, tracker.ErrNoProvider)
+	}
+
+	_, err = reg.Status(context.Background(), parcel.Tracking{Courier: "ups", TrackingNumber: "1Z0"})
+	if !errors.Is(err, tracker.ErrNotFound) {
+		t.Errorf("Registry.Status() error = %v, want %v", err, tracker.ErrNotFound)
+	}
+}
+
+func TestStatusText(t *testing.T) {
+	for s := tracker.StatusUnknown; s <= tracker.StatusReturned; s++ {
+		buf, err := json.Marshal(s)
+		if err != nil {
+			t.Fatalf("json.Marshal(%d) error %v", s, err)
+		}
+		var got tracker.Status
+		if err := json.Unmarshal(buf, &got); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error %v", buf, err)
+		}
+		if got != s {
+			t.Errorf("round trip %s = %s", s, got)
+		}
+	}
+
+	if _, err := tracker.ParseStatus("lost"); err == nil {
+		t.Error("ParseStatus() expected an error")
+	}
+}
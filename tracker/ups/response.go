@@ -0,0 +1,166 @@
+package ups
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"dev.freespoke.com/go-package-tracking/tracker"
+)
+
+type errorResponse struct {
+	Response struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"response"`
+}
+
+type trackResponse struct {
+	TrackResponse struct {
+		Shipment []struct {
+			InquiryNumber string `json:"inquiryNumber"`
+			Package       []pkg  `json:"package"`
+			Warnings      []struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"warnings"`
+		} `json:"shipment"`
+	} `json:"trackResponse"`
+}
+
+type pkg struct {
+	TrackingNumber string `json:"trackingNumber"`
+	DeliveryDate   []struct {
+		Type string `json:"type"`
+		Date string `json:"date"`
+	} `json:"deliveryDate"`
+	DeliveryTime struct {
+		Type      string `json:"type"`
+		StartTime string `json:"startTime"`
+		EndTime   string `json:"endTime"`
+	} `json:"deliveryTime"`
+	Activity      []activity `json:"activity"`
+	CurrentStatus struct {
+		Code        string `json:"code"`
+		Description string `json:"description"`
+	} `json:"currentStatus"`
+}
+
+type activity struct {
+	Location struct {
+		Address struct {
+			City          string `json:"city"`
+			StateProvince string `json:"stateProvince"`
+			PostalCode    string `json:"postalCode"`
+			CountryCode   string `json:"countryCode"`
+		} `json:"address"`
+	} `json:"location"`
+	Status struct {
+		Type        string `json:"type"`
+		Description string `json:"description"`
+		Code        string `json:"code"`
+		StatusCode  string `json:"statusCode"`
+	} `json:"status"`
+	Date    string `json:"date"`
+	Time    string `json:"time"`
+	GMTDate string `json:"gmtDate"`
+	GMTTime string `json:"gmtTime"`
+}
+
+// statusTypes maps UPS activity status types to normalized statuses.
+var statusTypes = map[string]tracker.Status{
+	"M":  tracker.StatusLabelCreated, // billing information received
+	"MV": tracker.StatusLabelCreated, // billing information voided
+	"P":  tracker.StatusInTransit,    // pickup
+	"I":  tracker.StatusInTransit,
+	"W":  tracker.StatusInTransit, // warehousing
+	"DO": tracker.StatusInTransit, // delivered origin CFS
+	"DD": tracker.StatusInTransit, // delivered destination CFS
+	"O":  tracker.StatusOutForDelivery,
+	"D":  tracker.StatusDelivered,
+	"X":  tracker.StatusException,
+	"RS": tracker.StatusReturned,
+}
+
+// shipment converts the response to a normalized shipment.
+func (r trackResponse) shipment(num string) (*tracker.Shipment, error) {
+	for _, s := range r.TrackResponse.Shipment {
+		if len(s.Package) == 0 {
+			if len(s.Warnings) > 0 {
+				return nil, fmt.Errorf("%w: %s", tracker.ErrNotFound, s.Warnings[0].Message)
+			}
+			continue
+		}
+
+		p := s.Package[0]
+		out := &tracker.Shipment{
+			Courier:        Courier,
+			TrackingNumber: num,
+			Events:         make([]tracker.Event, 0, len(p.Activity)),
+			ETA:            p.eta(),
+		}
+		for _, a := range p.Activity {
+			out.Events = append(out.Events, a.event())
+		}
+		out.Normalize()
+
+		return out, nil
+	}
+
+	return nil, tracker.ErrNotFound
+}
+
+// eta returns the scheduled delivery time, if any.
+func (p pkg) eta() time.Time {
+	for _, d := range p.DeliveryDate {
+		if d.Type != "SDD" && d.Type != "RDD" {
+			continue
+		}
+		clock := p.DeliveryTime.EndTime
+		if clock == "" {
+			clock = "000000"
+		}
+		t, err := parseTime(d.Date, clock)
+		if err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (a activity) event() tracker.Event {
+	t, err := parseTime(a.GMTDate, a.GMTTime)
+	if err != nil {
+		t, _ = parseTime(a.Date, a.Time)
+	}
+
+	addr := a.Location.Address
+	return tracker.Event{
+		Status:      statusTypes[strings.ToUpper(a.Status.Type)],
+		Code:        a.Status.Code,
+		Description: strings.TrimSpace(a.Status.Description),
+		Location: tracker.Location{
+			City:       addr.City,
+			State:      addr.StateProvince,
+			PostalCode: addr.PostalCode,
+			Country:    addr.CountryCode,
+		},
+		Time: t,
+	}
+}
+
+// parseTime parses UPS dates (YYYYMMDD) and times (HHMMSS) as UTC.
+func parseTime(date, clock string) (time.Time, error) {
+	if date == "" {
+		return time.Time{}, fmt.Errorf("ups: missing date")
+	}
+	clock = strings.ReplaceAll(clock, ":", "")
+	if clock == "" {
+		clock = "000000"
+	}
+
+	return time.Parse("20060102150405", date+clock)
+}
@@ -0,0 +1,155 @@
+// Package ups implements a tracker.StatusProvider for the UPS Track API.
+package ups
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/oauth"
+)
+
+const (
+	Courier = "ups"
+
+	// DefaultBaseURL is the UPS production API.
+	DefaultBaseURL = "https://onlinetools.ups.com"
+
+	// TestBaseURL is the UPS customer integration environment (CIE). The
+	// test numbers in internal/ups.MD only work against it.
+	TestBaseURL = "https://wwwcie.ups.com"
+
+	tokenPath = "/security/v1/oauth/token"
+	trackPath = "/api/track/v1/details/"
+
+	// Error codes returned by the Track API.
+	codeInvalid  = "151018"
+	codeNotFound = "151044"
+)
+
+// Config contains the UPS API credentials and options.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+
+	// Locale defaults to en_US.
+	Locale string
+
+	HTTPClient *http.Client
+}
+
+// Provider fetches shipment status from UPS.
+type Provider struct {
+	cfg   Config
+	token *oauth.Source
+}
+
+// New returns a UPS status provider.
+func New(cfg Config) *Provider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.Locale == "" {
+		cfg.Locale = "en_US"
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &Provider{
+		cfg: cfg,
+		token: &oauth.Source{
+			Courier:      Courier,
+			TokenURL:     cfg.BaseURL + tokenPath,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			Client:       cfg.HTTPClient,
+		},
+	}
+}
+
+func (p *Provider) Courier() string {
+	return Courier
+}
+
+// Status fetches the shipment activity for a tracking number.
+func (p *Provider) Status(ctx context.Context, t parcel.Tracking) (*tracker.Shipment, error) {
+	tok, err := p.token.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	q := url.Values{
+		"locale":          {p.cfg.Locale},
+		"returnSignature": {"false"},
+	}
+	u := p.cfg.BaseURL + trackPath + url.PathEscape(t.TrackingNumber) + "?" + q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("transId", strconv.FormatInt(time.Now().UnixNano(), 36))
+	req.Header.Set("transactionSrc", "go-package-tracking")
+
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// The token was revoked or expired early; fetch a new one next time.
+		p.token.Invalidate()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.StatusCode, body)
+	}
+
+	var r trackResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("ups: decoding response: %w", err)
+	}
+
+	return r.shipment(t.TrackingNumber)
+}
+
+// apiError converts an error response to a tracker error.
+func apiError(status int, body []byte) error {
+	var r errorResponse
+	_ = json.Unmarshal(body, &r)
+
+	e := &tracker.APIError{Courier: Courier, StatusCode: status}
+	if errs := r.Response.Errors; len(errs) > 0 {
+		e.Code = errs[0].Code
+		e.Message = errs[0].Message
+	}
+
+	switch e.Code {
+	case codeNotFound:
+		e.Err = tracker.ErrNotFound
+	case codeInvalid:
+		e.Err = tracker.ErrInvalid
+	}
+
+	return e
+}
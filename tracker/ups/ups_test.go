@@ -0,0 +1,220 @@
+package ups_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/replay"
+	"dev.freespoke.com/go-package-tracking/tracker/ups"
+)
+
+const (
+	clientID     = "client"
+	clientSecret = "secret"
+)
+
+// newServer starts a stand-in for the UPS API replaying the test numbers
+// from internal/ups.MD with testdata/<number>.json.
+func newServer(t *testing.T) *replay.Server {
+	return replay.NewServer(t, replay.Config{
+		TokenPath:    "/security/v1/oauth/token",
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Number: func(r *http.Request) string {
+			return strings.TrimPrefix(r.URL.Path, "/api/track/v1/details/")
+		},
+		Dir: "testdata",
+		Status: map[string]int{
+			"1Z12345E020527079":  http.StatusBadRequest,
+			"1Z12345E1505270452": http.StatusNotFound,
+		},
+	})
+}
+
+func TestStatus(t *testing.T) {
+	srv := newServer(t)
+	p := ups.New(ups.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		BaseURL:      srv.URL,
+		HTTPClient:   srv.Client(),
+	})
+
+	tests := []struct {
+		num     string
+		status  tracker.Status
+		events  int
+		last    string
+		eta     time.Time
+		wantErr error
+	}{
+		{
+			num:    "1Z12345E0205271688",
+			status: tracker.StatusDelivered,
+			events: 5,
+			last:   "DELIVERED",
+		},
+		{
+			num:    "1Z12345E6605272234",
+			status: tracker.StatusDelivered,
+			events: 1,
+			last:   "DELIVERED",
+		},
+		{
+			num:    "1Z12345E1305277940",
+			status: tracker.StatusInTransit,
+			events: 1,
+			last:   "ORIGIN SCAN",
+			eta:    time.Date(2010, 6, 10, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			num:    "1Z12345E6205277936",
+			status: tracker.StatusException,
+			events: 3,
+			last:   "THE RECEIVER WAS NOT AVAILABLE FOR DELIVERY. WE'LL MAKE A FINAL ATTEMPT THE NEXT BUSINESS DAY.",
+			eta:    time.Date(2010, 6, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			num:     "1Z12345E020527079",
+			wantErr: tracker.ErrInvalid,
+		},
+		{
+			num:     "1Z12345E1505270452",
+			wantErr: tracker.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.num, func(t *testing.T) {
+			got, err := p.Status(context.Background(), parcel.Tracking{Courier: "ups", TrackingNumber: tt.num})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Status() error = %v, want %v", err, tt.wantErr)
+				}
+				var apiErr *tracker.APIError
+				if !errors.As(err, &apiErr) {
+					t.Errorf("Status() error = %T, want *tracker.APIError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Status() error %v", err)
+			}
+			if got.Status != tt.status {
+				t.Errorf("Status() status = %s, want %s", got.Status, tt.status)
+			}
+			if len(got.Events) != tt.events {
+				t.Fatalf("Status() events = %d, want %d", len(got.Events), tt.events)
+			}
+			last, _ := got.Latest()
+			if last.Description != tt.last {
+				t.Errorf("Status() latest = %q, want %q", last.Description, tt.last)
+			}
+			if !got.ETA.Equal(tt.eta) {
+				t.Errorf("Status() ETA = %v, want %v", got.ETA, tt.eta)
+			}
+		})
+	}
+
+	if n := srv.Tokens(); n != 1 {
+		t.Errorf("expected the access token to be cached, got %d token requests", n)
+	}
+}
+
+func TestStatusOrdered(t *testing.T) {
+	srv := newServer(t)
+	p := ups.New(ups.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		BaseURL:      srv.URL,
+		HTTPClient:   srv.Client(),
+	})
+
+	got, err := p.Status(context.Background(), parcel.Tracking{Courier: "ups", TrackingNumber: "1Z12345E0205271688"})
+	if err != nil {
+		t.Fatalf("Status() error %v", err)
+	}
+
+	want := []tracker.Status{
+		tracker.StatusLabelCreated,
+		tracker.StatusInTransit,
+		tracker.StatusInTransit,
+		tracker.StatusOutForDelivery,
+		tracker.StatusDelivered,
+	}
+	for i, e := range got.Events {
+		if e.Status != want[i] {
+			t.Errorf("event %d status = %s, want %s", i, e.Status, want[i])
+		}
+	}
+	if loc := got.Events[4].Location.String(); loc != "ANYTOWN, GA, 30340, US" {
+		t.Errorf("event location = %q", loc)
+	}
+	if ts := got.Events[4].Time; !ts.Equal(time.Date(2010, 6, 8, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("event time = %v", ts)
+	}
+}
+
+func TestBadCredentials(t *testing.T) {
+	srv := newServer(t)
+	p := ups.New(ups.Config{
+		ClientID:     clientID,
+		ClientSecret: "wrong",
+		BaseURL:      srv.URL,
+		HTTPClient:   srv.Client(),
+	})
+
+	_, err := p.Status(context.Background(), parcel.Tracking{TrackingNumber: "1Z12345E0205271688"})
+	var apiErr *tracker.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Temporary() {
+		t.Errorf("Status() error = %v, want a 401 *tracker.APIError", err)
+	}
+}
+
+func TestTokenUnavailable(t *testing.T) {
+	srv := replay.NewServer(t, replay.Config{
+		TokenPath:   "/security/v1/oauth/token",
+		TokenStatus: http.StatusServiceUnavailable,
+	})
+	p := ups.New(ups.Config{BaseURL: srv.URL, HTTPClient: srv.Client()})
+
+	_, err := p.Status(context.Background(), parcel.Tracking{TrackingNumber: "1Z12345E0205271688"})
+	var apiErr *tracker.APIError
+	if !errors.As(err, &apiErr) || apiErr.Courier != ups.Courier || !apiErr.Temporary() {
+		t.Errorf("Status() error = %v, want a temporary *tracker.APIError", err)
+	}
+}
+
+func TestRevokedToken(t *testing.T) {
+	srv := newServer(t)
+	p := ups.New(ups.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		BaseURL:      srv.URL,
+		HTTPClient:   srv.Client(),
+	})
+	tr := parcel.Tracking{Courier: "ups", TrackingNumber: "1Z12345E0205271688"}
+
+	if _, err := p.Status(context.Background(), tr); err != nil {
+		t.Fatalf("Status() error %v", err)
+	}
+	srv.Revoke()
+
+	_, err := p.Status(context.Background(), tr)
+	var apiErr *tracker.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Status() error = %v, want a 401 *tracker.APIError", err)
+	}
+	if _, err := p.Status(context.Background(), tr); err != nil {
+		t.Errorf("Status() after a 401 error %v", err)
+	}
+	if n := srv.Tokens(); n != 2 {
+		t.Errorf("expected a new token after the 401, got %d token requests", n)
+	}
+}
@@ -0,0 +1,102 @@
+package ups
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/webhook"
+)
+
+// credentialHeader carries the credential chosen when subscribing to UPS
+// Track Alert notifications.
+const credentialHeader = "credential"
+
+// Webhook parses UPS Track Alert push notifications.
+type Webhook struct {
+	credential string
+}
+
+// NewWebhook returns a webhook.Parser verifying notifications against the
+// Track Alert subscription credential.
+func NewWebhook(credential string) *Webhook {
+	return &Webhook{credential: credential}
+}
+
+func (w *Webhook) Courier() string {
+	return Courier
+}
+
+func (w *Webhook) Verify(r *http.Request, body []byte) error {
+	got := r.Header.Get(credentialHeader)
+	if w.credential == "" || subtle.ConstantTimeCompare([]byte(got), []byte(w.credential)) != 1 {
+		return webhook.ErrSignature
+	}
+
+	return nil
+}
+
+type alert struct {
+	TrackingNumber        string `json:"trackingNumber"`
+	LocalActivityDate     string `json:"localActivityDate"`
+	LocalActivityTime     string `json:"localActivityTime"`
+	GMTActivityDate       string `json:"gmtActivityDate"`
+	GMTActivityTime       string `json:"gmtActivityTime"`
+	ScheduledDeliveryDate string `json:"scheduledDeliveryDate"`
+	DeliveryEndTime       string `json:"deliveryEndTime"`
+	ActivityLocation      struct {
+		City          string `json:"city"`
+		StateProvince string `json:"stateProvince"`
+		PostalCode    string `json:"postalCode"`
+		Country       string `json:"country"`
+	} `json:"activityLocation"`
+	ActivityStatus struct {
+		Type        string `json:"type"`
+		Code        string `json:"code"`
+		Description string `json:"description"`
+	} `json:"activityStatus"`
+}
+
+// Parse decodes a single Track Alert notification.
+func (w *Webhook) Parse(body []byte) ([]webhook.Notification, error) {
+	var a alert
+	if err := json.Unmarshal(body, &a); err != nil {
+		return nil, fmt.Errorf("ups: decoding notification: %w", err)
+	}
+	if a.TrackingNumber == "" {
+		return nil, fmt.Errorf("ups: notification without tracking number")
+	}
+
+	ts, err := parseTime(a.GMTActivityDate, a.GMTActivityTime)
+	if err != nil {
+		ts, _ = parseTime(a.LocalActivityDate, a.LocalActivityTime)
+	}
+
+	n := webhook.Notification{
+		TrackingNumber: a.TrackingNumber,
+		Events: []tracker.Event{{
+			Status:      statusTypes[strings.ToUpper(a.ActivityStatus.Type)],
+			Code:        a.ActivityStatus.Code,
+			Description: strings.TrimSpace(a.ActivityStatus.Description),
+			Location: tracker.Location{
+				City:       a.ActivityLocation.City,
+				State:      a.ActivityLocation.StateProvince,
+				PostalCode: a.ActivityLocation.PostalCode,
+				Country:    a.ActivityLocation.Country,
+			},
+			Time: ts,
+		}},
+	}
+	if a.ScheduledDeliveryDate != "" {
+		clock := a.DeliveryEndTime
+		if clock == "" {
+			clock = "000000"
+		}
+		n.ETA, _ = parseTime(a.ScheduledDeliveryDate, clock)
+	}
+
+	return []webhook.Notification{n}, nil
+}
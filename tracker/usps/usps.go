@@ -0,0 +1,246 @@
+// Package usps implements a tracker.StatusProvider for the USPS Tracking v3
+// API.
+package usps
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/oauth"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/timestamp"
+)
+
+const (
+	Courier = "usps"
+
+	// DefaultBaseURL is the USPS production API.
+	DefaultBaseURL = "https://apis.usps.com"
+
+	// TestBaseURL is the USPS testing environment.
+	TestBaseURL = "https://apis-tem.usps.com"
+
+	tokenPath = "/oauth2/v3/token"
+	trackPath = "/tracking/v3/tracking/"
+)
+
+// Config contains the USPS API credentials and options.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+
+	// BaseURL defaults to DefaultBaseURL.
+	BaseURL string
+
+	HTTPClient *http.Client
+}
+
+// Provider fetches shipment status from USPS.
+type Provider struct {
+	cfg   Config
+	token *oauth.Source
+}
+
+// New returns a USPS status provider.
+func New(cfg Config) *Provider {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultBaseURL
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = http.DefaultClient
+	}
+
+	return &Provider{
+		cfg: cfg,
+		token: &oauth.Source{
+			Courier:      Courier,
+			TokenURL:     cfg.BaseURL + tokenPath,
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			InParams:     true,
+			Client:       cfg.HTTPClient,
+		},
+	}
+}
+
+func (p *Provider) Courier() string {
+	return Courier
+}
+
+// Status fetches the tracking events for a tracking number.
+func (p *Provider) Status(ctx context.Context, t parcel.Tracking) (*tracker.Shipment, error) {
+	tok, err := p.token.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u := p.cfg.BaseURL + trackPath + url.PathEscape(t.TrackingNumber) + "?expand=DETAIL"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		// The token was revoked or expired early; fetch a new one next time.
+		p.token.Invalidate()
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp.StatusCode, body)
+	}
+
+	var r trackResponse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("usps: decoding response: %w", err)
+	}
+
+	return r.shipment(t.TrackingNumber), nil
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Errors  []struct {
+			Code   string `json:"code"`
+			Title  string `json:"title"`
+			Detail string `json:"detail"`
+		} `json:"errors"`
+	} `json:"error"`
+}
+
+// apiError converts an error response to a tracker error.
+func apiError(status int, body []byte) error {
+	var r errorResponse
+	_ = json.Unmarshal(body, &r)
+
+	e := &tracker.APIError{
+		Courier:    Courier,
+		StatusCode: status,
+		Code:       r.Error.Code,
+		Message:    r.Error.Message,
+	}
+	if errs := r.Error.Errors; len(errs) > 0 {
+		e.Code = errs[0].Code
+		e.Message = errs[0].Detail
+	}
+
+	switch {
+	case status == http.StatusNotFound:
+		e.Err = tracker.ErrNotFound
+	case status == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Message), "tracking number"):
+		e.Err = tracker.ErrInvalid
+	}
+
+	return e
+}
+
+type trackResponse struct {
+	TrackingNumber            string  `json:"trackingNumber"`
+	Status                    string  `json:"status"`
+	StatusCategory            string  `json:"statusCategory"`
+	ExpectedDeliveryTimeStamp string  `json:"expectedDeliveryTimeStamp"`
+	TrackingEvents            []event `json:"trackingEvents"`
+}
+
+type event struct {
+	EventType      string `json:"eventType"`
+	EventTimestamp string `json:"eventTimestamp"`
+	GMTTimestamp   string `json:"GMTTimestamp"`
+	EventCountry   string `json:"eventCountry"`
+	EventCity      string `json:"eventCity"`
+	EventState     string `json:"eventState"`
+	EventZIP       string `json:"eventZIP"`
+	EventCode      string `json:"eventCode"`
+}
+
+// eventCodes maps USPS event codes to normalized statuses.
+var eventCodes = map[string]tracker.Status{
+	"GX": tracker.StatusLabelCreated, // shipping label created
+	"MA": tracker.StatusLabelCreated, // pre-shipment info sent
+	"03": tracker.StatusInTransit,    // accepted at origin
+	"10": tracker.StatusInTransit,    // processed through facility
+	"07": tracker.StatusInTransit,    // arrival at unit
+	"OA": tracker.StatusInTransit,    // accepted at origin
+	"L1": tracker.StatusInTransit,    // departed facility
+	"OF": tracker.StatusOutForDelivery,
+	"01": tracker.StatusDelivered,
+	"02": tracker.StatusException, // notice left
+	"04": tracker.StatusException, // refused
+	"05": tracker.StatusException, // undeliverable as addressed
+	"21": tracker.StatusException, // no such number
+	"22": tracker.StatusException, // insufficient address
+	"09": tracker.StatusReturned,  // return to sender
+	"RT": tracker.StatusReturned,
+}
+
+// statusCategories maps the USPS status category, used when the event code
+// is unknown.
+var statusCategories = map[string]tracker.Status{
+	"pre-shipment":     tracker.StatusLabelCreated,
+	"accepted":         tracker.StatusInTransit,
+	"in transit":       tracker.StatusInTransit,
+	"out for delivery": tracker.StatusOutForDelivery,
+	"delivered":        tracker.StatusDelivered,
+	"alert":            tracker.StatusException,
+	"return to sender": tracker.StatusReturned,
+}
+
+// shipment converts the response to a normalized shipment.
+func (r trackResponse) shipment(num string) *tracker.Shipment {
+	out := &tracker.Shipment{
+		Courier:        Courier,
+		TrackingNumber: num,
+		Status:         statusCategories[strings.ToLower(r.StatusCategory)],
+		Events:         make([]tracker.Event, 0, len(r.TrackingEvents)),
+		ETA:            timestamp.Parse(r.ExpectedDeliveryTimeStamp),
+	}
+	if s := strings.ToLower(r.Status); strings.Contains(s, "return to sender") || strings.Contains(s, "original sender") {
+		out.Status = tracker.StatusReturned
+	}
+
+	for _, e := range r.TrackingEvents {
+		ts := timestamp.Parse(e.GMTTimestamp)
+		if ts.IsZero() {
+			ts = timestamp.Parse(e.EventTimestamp)
+		}
+		status, ok := eventCodes[e.EventCode]
+		if !ok {
+			status = statusCategories[strings.ToLower(e.EventType)]
+		}
+		out.Events = append(out.Events, tracker.Event{
+			Status:      status,
+			Code:        e.EventCode,
+			Description: e.EventType,
+			Location: tracker.Location{
+				City:       e.EventCity,
+				State:      e.EventState,
+				PostalCode: e.EventZIP,
+				Country:    e.EventCountry,
+			},
+			Time: ts,
+		})
+	}
+	out.Normalize()
+
+	return out
+}
@@ -0,0 +1,69 @@
+package usps_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/internal/replay"
+	"dev.freespoke.com/go-package-tracking/tracker/usps"
+)
+
+func TestStatus(t *testing.T) {
+	srv := replay.NewServer(t, replay.Config{
+		TokenPath:    "/oauth2/v3/token",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		InParams:     true,
+		Number: func(r *http.Request) string {
+			return strings.TrimPrefix(r.URL.Path, "/tracking/v3/tracking/")
+		},
+		Dir:    "testdata",
+		Status: map[string]int{"9400100000000000000000": http.StatusNotFound},
+	})
+	p := usps.New(usps.Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		BaseURL:      srv.URL,
+		HTTPClient:   srv.Client(),
+	})
+
+	tests := []struct {
+		num     string
+		status  tracker.Status
+		wantErr error
+	}{
+		{num: "9400111899223334444555", status: tracker.StatusDelivered},
+		{num: "9205500000000000000000", status: tracker.StatusInTransit},
+		{num: "9270190164917312751089", status: tracker.StatusReturned},
+		{num: "9400100000000000000000", wantErr: tracker.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.num, func(t *testing.T) {
+			got, err := p.Status(context.Background(), parcel.Tracking{Courier: usps.Courier, TrackingNumber: tt.num})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Status() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Status() error %v", err)
+			}
+			if got.Status != tt.status {
+				t.Errorf("Status() status = %s, want %s", got.Status, tt.status)
+			}
+			replay.Golden(t, filepath.Join("testdata", tt.num+".golden"), got)
+		})
+	}
+
+	if n := srv.Tokens(); n != 1 {
+		t.Errorf("expected the access token to be cached, got %d token requests", n)
+	}
+}
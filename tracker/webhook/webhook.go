@@ -0,0 +1,232 @@
+// Package webhook receives courier push notifications and feeds them to a
+// tracker.Monitor.
+//
+// Courier specific parsing and signature verification is implemented by a
+// Parser; see ups.NewWebhook and fedex.NewWebhook.
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+	"dev.freespoke.com/go-package-tracking/tracker"
+)
+
+var ErrSignature = errors.New("invalid webhook signature")
+
+// DefaultMaxBody is the largest notification body accepted by default.
+const DefaultMaxBody = 1 << 20
+
+// Parser verifies and decodes a courier's push notifications.
+type Parser interface {
+	// Courier returns the courier code of the notifications.
+	Courier() string
+
+	// Verify checks the request signature or shared secret over the raw
+	// body. It returns ErrSignature if verification fails.
+	Verify(r *http.Request, body []byte) error
+
+	// Parse decodes the notifications in the body.
+	Parse(body []byte) ([]Notification, error)
+}
+
+// Notification is a single normalized courier update.
+type Notification struct {
+	// ID identifies the notification for idempotency. The handler derives
+	// one from the body if empty.
+	ID             string
+	TrackingNumber string
+
+	// Status is the shipment status, if the courier reports one. Otherwise
+	// it is derived from the events.
+	Status tracker.Status
+	ETA    time.Time
+	Events []tracker.Event
+}
+
+// Config configures a Handler. Only Parser and Monitor are required.
+type Config struct {
+	Parser  Parser
+	Monitor *tracker.Monitor
+
+	// Seen records processed notification IDs. Defaults to an in-memory
+	// set remembering IDs for 24 hours.
+	Seen Seen
+
+	// MaxBody limits the request body size. Defaults to DefaultMaxBody.
+	MaxBody int64
+
+	// ErrorLog receives errors for notifications that were acknowledged
+	// but not applied, such as unknown tracking numbers. Defaults to the
+	// standard logger.
+	ErrorLog *log.Logger
+}
+
+// Handler is an http.Handler for a courier's push notifications.
+type Handler struct {
+	cfg Config
+}
+
+// NewHandler returns a handler for cfg.
+func NewHandler(cfg Config) *Handler {
+	if cfg.Seen == nil {
+		cfg.Seen = NewMemorySeen(24 * time.Hour)
+	}
+	if cfg.MaxBody <= 0 {
+		cfg.MaxBody = DefaultMaxBody
+	}
+	if cfg.ErrorLog == nil {
+		cfg.ErrorLog = log.Default()
+	}
+
+	return &Handler{cfg: cfg}
+}
+
+// ServeHTTP verifies, parses and applies a notification. Notifications that
+// can't be applied are still acknowledged so the courier doesn't retry
+// them; only unverified or malformed requests are rejected.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, h.cfg.MaxBody+1))
+	if err != nil {
+		http.Error(w, "reading body", http.StatusBadRequest)
+		return
+	}
+	if int64(len(body)) > h.cfg.MaxBody {
+		http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := h.cfg.Parser.Verify(r, body); err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	notes, err := h.cfg.Parser.Parse(body)
+	if err != nil {
+		http.Error(w, "invalid notification", http.StatusBadRequest)
+		return
+	}
+
+	for i, n := range notes {
+		if n.ID == "" {
+			n.ID = bodyID(body, i)
+		}
+		if !h.cfg.Seen.Mark(h.cfg.Parser.Courier() + ":" + n.ID) {
+			continue
+		}
+		if err := h.apply(n); err != nil {
+			h.cfg.ErrorLog.Printf("webhook: %s %s: %v", h.cfg.Parser.Courier(), n.TrackingNumber, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply matches a notification to a Track result and updates the monitor.
+func (h *Handler) apply(n Notification) error {
+	t, err := Match(h.cfg.Parser.Courier(), n.TrackingNumber)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.cfg.Monitor.Update(&tracker.Shipment{
+		Courier:        t.Courier,
+		TrackingNumber: t.TrackingNumber,
+		Status:         n.Status,
+		ETA:            n.ETA,
+		Events:         n.Events,
+	})
+	if errors.Is(err, tracker.ErrTransition) {
+		// Stale notifications are expected when couriers retry.
+		return nil
+	}
+
+	return err
+}
+
+var ErrNoMatch = errors.New("tracking number not recognized for courier")
+
+// Match returns the parcel.Track result for a tracking number from a
+// courier.
+func Match(courier, trackingNumber string) (parcel.Tracking, error) {
+	res, err := parcel.Track(trackingNumber)
+	if err != nil {
+		return parcel.Tracking{}, err
+	}
+	for _, t := range res {
+		if t.Courier == courier {
+			return t, nil
+		}
+	}
+
+	return parcel.Tracking{}, ErrNoMatch
+}
+
+// bodyID derives a notification ID from the body and its position in it.
+func bodyID(body []byte, i int) string {
+	h := sha256.New()
+	h.Write(body)
+	h.Write(binary.AppendUvarint(nil, uint64(i)))
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Seen records processed notifications.
+type Seen interface {
+	// Mark records an ID. It returns false if the ID was already recorded.
+	Mark(id string) bool
+}
+
+// MemorySeen remembers IDs in memory for a fixed time.
+type MemorySeen struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu  sync.Mutex
+	ids map[string]time.Time
+}
+
+// NewMemorySeen returns a set remembering IDs for ttl.
+func NewMemorySeen(ttl time.Duration) *MemorySeen {
+	return &MemorySeen{
+		ttl: ttl,
+		now: time.Now,
+		ids: make(map[string]time.Time),
+	}
+}
+
+func (s *MemorySeen) Mark(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if exp, ok := s.ids[id]; ok && now.Before(exp) {
+		return false
+	}
+	s.ids[id] = now.Add(s.ttl)
+
+	// Expire old IDs as the set grows.
+	if len(s.ids)%1024 == 0 {
+		for k, exp := range s.ids {
+			if !now.Before(exp) {
+				delete(s.ids, k)
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,206 @@
+package webhook_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"dev.freespoke.com/go-package-tracking/tracker"
+	"dev.freespoke.com/go-package-tracking/tracker/fedex"
+	"dev.freespoke.com/go-package-tracking/tracker/ups"
+	"dev.freespoke.com/go-package-tracking/tracker/webhook"
+)
+
+const upsAlert = `{
+  "trackingNumber": "1Z5R89390357567127",
+  "localActivityDate": "20230601",
+  "localActivityTime": "101500",
+  "gmtActivityDate": "20230601",
+  "gmtActivityTime": "141500",
+  "scheduledDeliveryDate": "20230605",
+  "activityLocation": {"city": "ATLANTA", "stateProvince": "GA", "postalCode": "30301", "country": "US"},
+  "activityStatus": {"type": "I", "code": "OR", "description": "Origin Scan"}
+}`
+
+const fedexNotification = `{
+  "trackingEventId": "evt-1",
+  "trackingNumber": "986578788855",
+  "trackResults": {
+    "latestStatusDetail": {"code": "OD", "derivedCode": "OD", "description": "On FedEx vehicle for delivery"},
+    "scanEvents": [
+      {
+        "date": "2023-06-02T07:40:00-05:00",
+        "eventType": "OD",
+        "eventDescription": "On FedEx vehicle for delivery",
+        "scanLocation": {"city": "AUSTIN", "stateOrProvinceCode": "TX", "countryCode": "US"}
+      }
+    ]
+  }
+}`
+
+func post(h http.Handler, body string, header map[string]string) int {
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func sign(token, body string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	mac.Write([]byte(body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestUPS(t *testing.T) {
+	m := tracker.NewMonitor()
+	var changes []tracker.Change
+	m.Subscribe(func(c tracker.Change) { changes = append(changes, c) })
+
+	h := webhook.NewHandler(webhook.Config{
+		Parser:  ups.NewWebhook("s3cret"),
+		Monitor: m,
+	})
+
+	if code := post(h, upsAlert, map[string]string{"credential": "wrong"}); code != http.StatusUnauthorized {
+		t.Errorf("bad credential status = %d, want 401", code)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("unverified notification applied: %v", changes)
+	}
+
+	if code := post(h, upsAlert, map[string]string{"credential": "s3cret"}); code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", code)
+	}
+	s, ok := m.Shipment("ups", "1Z5R89390357567127")
+	if !ok {
+		t.Fatal("notification not applied to monitor")
+	}
+	if s.Status != tracker.StatusInTransit || len(s.Events) != 1 || s.ETA.IsZero() {
+		t.Errorf("shipment = %+v", s)
+	}
+	if loc := s.Events[0].Location.String(); loc != "ATLANTA, GA, 30301, US" {
+		t.Errorf("event location = %q", loc)
+	}
+
+	// Replays are acknowledged without changes.
+	n := len(changes)
+	if code := post(h, upsAlert, map[string]string{"credential": "s3cret"}); code != http.StatusNoContent {
+		t.Errorf("replay status = %d, want 204", code)
+	}
+	if len(changes) != n {
+		t.Errorf("replay produced changes: %v", changes[n:])
+	}
+
+	if code := post(h, `{"trackingNumber":`, map[string]string{"credential": "s3cret"}); code != http.StatusBadRequest {
+		t.Errorf("malformed status = %d, want 400", code)
+	}
+}
+
+func TestFedEx(t *testing.T) {
+	m := tracker.NewMonitor()
+	h := webhook.NewHandler(webhook.Config{
+		Parser:  fedex.NewWebhook("token"),
+		Monitor: m,
+	})
+
+	if code := post(h, fedexNotification, map[string]string{"X-Fdx-Signature": sign("other", fedexNotification)}); code != http.StatusUnauthorized {
+		t.Errorf("bad signature status = %d, want 401", code)
+	}
+
+	if code := post(h, fedexNotification, map[string]string{"X-Fdx-Signature": sign("token", fedexNotification)}); code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", code)
+	}
+	s, ok := m.Shipment("fedex", "986578788855")
+	if !ok {
+		t.Fatal("notification not applied to monitor")
+	}
+	if s.Status != tracker.StatusOutForDelivery {
+		t.Errorf("shipment status = %s, want out_for_delivery", s.Status)
+	}
+}
+
+func TestUnknownNumber(t *testing.T) {
+	var logs bytes.Buffer
+	m := tracker.NewMonitor()
+	h := webhook.NewHandler(webhook.Config{
+		Parser:   ups.NewWebhook("s3cret"),
+		Monitor:  m,
+		ErrorLog: log.New(&logs, "", 0),
+	})
+
+	body := `{"trackingNumber":"NOTANUMBER","activityStatus":{"type":"I"}}`
+	if code := post(h, body, map[string]string{"credential": "s3cret"}); code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", code)
+	}
+	if logs.Len() == 0 {
+		t.Error("expected the unmatched notification to be logged")
+	}
+}
+
+func TestMethodAndSize(t *testing.T) {
+	h := webhook.NewHandler(webhook.Config{
+		Parser:   ups.NewWebhook("s3cret"),
+		Monitor:  tracker.NewMonitor(),
+		MaxBody:  16,
+		ErrorLog: log.New(io.Discard, "", 0),
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want 405", rec.Code)
+	}
+
+	if code := post(h, upsAlert, map[string]string{"credential": "s3cret"}); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body status = %d, want 413", code)
+	}
+}
+
+// batchParser returns n notifications without IDs for any body.
+type batchParser int
+
+func (p batchParser) Courier() string                        { return "ups" }
+func (p batchParser) Verify(r *http.Request, b []byte) error { return nil }
+
+func (p batchParser) Parse(body []byte) ([]webhook.Notification, error) {
+	return make([]webhook.Notification, p), nil
+}
+
+// seenIDs records every ID marked.
+type seenIDs map[string]bool
+
+func (s seenIDs) Mark(id string) bool {
+	if s[id] {
+		return false
+	}
+	s[id] = true
+
+	return true
+}
+
+func TestDerivedIDs(t *testing.T) {
+	seen := seenIDs{}
+	h := webhook.NewHandler(webhook.Config{
+		Parser:   batchParser(300),
+		Monitor:  tracker.NewMonitor(),
+		Seen:     seen,
+		ErrorLog: log.New(io.Discard, "", 0),
+	})
+
+	if code := post(h, `{}`, nil); code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204", code)
+	}
+	if len(seen) != 300 {
+		t.Errorf("derived %d distinct IDs for 300 notifications", len(seen))
+	}
+}
@@ -0,0 +1,157 @@
+package parcel
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// URL kinds accepted by Tracking.URL.
+const (
+	URLWeb    = "web"
+	URLMobile = "mobile"
+)
+
+// URLTemplate is a tracking URL for a courier or a single service.
+//
+// Template placeholders are written as {Name} and are filled from the
+// Tracking result: {TrackingNumber}, {SerialNumber}, {CheckDigit} and any
+// named regex group extracted into Details, such as {DestinationZip}.
+type URLTemplate struct {
+	Courier  string // courier code, required
+	Service  string // service ID, applies to every service of the courier if empty
+	Locale   string // BCP 47 tag such as "fr-CA", any locale if empty
+	Kind     string // URLWeb if empty
+	Template string
+}
+
+var (
+	urlMu        sync.RWMutex
+	urlTemplates = []URLTemplate{
+		{Courier: "canada_post", Locale: "en", Template: "https://www.canadapost-postescanada.ca/track-reperage/en#/search?searchFor={TrackingNumber}"},
+		{Courier: "canada_post", Locale: "fr", Template: "https://www.canadapost-postescanada.ca/track-reperage/fr#/search?searchFor={TrackingNumber}"},
+		{Courier: "dhl", Locale: "en-US", Template: "https://www.dhl.com/us-en/home/tracking.html?tracking-id={TrackingNumber}"},
+		{Courier: "dhl", Locale: "en-GB", Template: "https://www.dhl.com/gb-en/home/tracking.html?tracking-id={TrackingNumber}"},
+		{Courier: "dhl", Locale: "en-CA", Template: "https://www.dhl.com/ca-en/home/tracking.html?tracking-id={TrackingNumber}"},
+		{Courier: "dhl", Locale: "fr-CA", Template: "https://www.dhl.com/ca-fr/home/tracking.html?tracking-id={TrackingNumber}"},
+		{Courier: "dhl", Locale: "de", Template: "https://www.dhl.com/de-de/home/tracking.html?tracking-id={TrackingNumber}"},
+		{Courier: "dhl", Locale: "fr", Template: "https://www.dhl.com/fr-fr/home/tracking.html?tracking-id={TrackingNumber}"},
+		{Courier: "ups", Locale: "en-US", Template: "https://www.ups.com/track?loc=en_US&tracknum={TrackingNumber}"},
+		{Courier: "ups", Locale: "en-CA", Template: "https://www.ups.com/track?loc=en_CA&tracknum={TrackingNumber}"},
+		{Courier: "ups", Locale: "fr-CA", Template: "https://www.ups.com/track?loc=fr_CA&tracknum={TrackingNumber}"},
+		{Courier: "ups", Locale: "en-GB", Template: "https://www.ups.com/track?loc=en_GB&tracknum={TrackingNumber}"},
+		{Courier: "ups", Locale: "de", Template: "https://www.ups.com/track?loc=de_DE&tracknum={TrackingNumber}"},
+		{Courier: "ups", Locale: "fr", Template: "https://www.ups.com/track?loc=fr_FR&tracknum={TrackingNumber}"},
+		{Courier: "fedex", Locale: "en-CA", Template: "https://www.fedex.com/fedextrack/?trknbr={TrackingNumber}&locale=en_CA"},
+		{Courier: "fedex", Locale: "fr-CA", Template: "https://www.fedex.com/fedextrack/?trknbr={TrackingNumber}&locale=fr_CA"},
+	}
+
+	placeholder = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+)
+
+// RegisterURL adds a tracking URL template. Templates registered later take
+// precedence over earlier ones for the same courier, service, locale and kind.
+func RegisterURL(t URLTemplate) {
+	urlMu.Lock()
+	defer urlMu.Unlock()
+
+	urlTemplates = append(urlTemplates, t)
+}
+
+// URL returns the tracking URL for a locale and kind.
+// An empty kind means URLWeb. Locales fall back from the full tag to its
+// language and then to the locale independent URL. If no template applies,
+// web URLs fall back to TrackingURL and other kinds return an empty string.
+func (t Tracking) URL(locale, kind string) string {
+	if kind == "" {
+		kind = URLWeb
+	}
+
+	locale = normalizeLocale(locale)
+	locales := []string{locale}
+	if i := strings.Index(locale, "-"); i > 0 {
+		locales = append(locales, locale[:i])
+	}
+	if locale != "" {
+		locales = append(locales, "")
+	}
+
+	urlMu.RLock()
+	defer urlMu.RUnlock()
+
+	for _, l := range locales {
+		// Service specific templates win over courier wide templates.
+		for _, specific := range []bool{true, false} {
+			for i := len(urlTemplates) - 1; i >= 0; i-- {
+				tmpl := urlTemplates[i]
+				if !t.templateMatches(tmpl, l, kind, specific) {
+					continue
+				}
+				if u, ok := t.expand(tmpl.Template); ok {
+					return u
+				}
+			}
+		}
+	}
+
+	if kind == URLWeb {
+		return t.TrackingURL
+	}
+
+	return ""
+}
+
+// templateMatches reports whether a template applies to the result.
+func (t Tracking) templateMatches(tmpl URLTemplate, locale, kind string, specific bool) bool {
+	if tmpl.Courier != t.Courier {
+		return false
+	}
+	if specific != (tmpl.Service != "") {
+		return false
+	}
+	if specific && tmpl.Service != t.ServiceID {
+		return false
+	}
+	if k := tmpl.Kind; k != kind && !(k == "" && kind == URLWeb) {
+		return false
+	}
+
+	return normalizeLocale(tmpl.Locale) == locale
+}
+
+// expand fills the template placeholders. It reports false if a placeholder
+// has no value for this result.
+func (t Tracking) expand(tmpl string) (string, bool) {
+	ok := true
+	out := placeholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		var v string
+		switch name := m[1 : len(m)-1]; name {
+		case "TrackingNumber":
+			v = t.TrackingNumber
+		case "SerialNumber":
+			v = t.SerialNumber
+		case "CheckDigit":
+			v = t.CheckDigit
+		default:
+			v = t.Details[name]
+		}
+		if v == "" {
+			ok = false
+		}
+		return url.QueryEscape(v)
+	})
+
+	return out, ok
+}
+
+// normalizeLocale converts a locale such as "fr_ca" to "fr-CA".
+func normalizeLocale(l string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(l), "_", "-"), "-")
+	parts[0] = strings.ToLower(parts[0])
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i])
+	}
+
+	return strings.Join(parts, "-")
+}
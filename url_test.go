@@ -0,0 +1,108 @@
+package parcel_test
+
+import (
+	"testing"
+
+	parcel "dev.freespoke.com/go-package-tracking"
+)
+
+func TestTrackingURL(t *testing.T) {
+	t.Cleanup(parcel.SaveURLTemplates())
+
+	parcel.RegisterURL(parcel.URLTemplate{
+		Courier:  "usps",
+		Service:  "usps_91",
+		Kind:     parcel.URLMobile,
+		Template: "usps://track/{ServiceType}/{SerialNumber}/{CheckDigit}",
+	})
+	parcel.RegisterURL(parcel.URLTemplate{
+		Courier:  "usps",
+		Kind:     parcel.URLMobile,
+		Template: "usps://zip/{DestinationZip}/{TrackingNumber}",
+	})
+
+	tests := []struct {
+		name   string
+		in     string
+		svc    string
+		locale string
+		kind   string
+		want   string
+	}{
+		{
+			name:   "canada post fr",
+			in:     "7035114477138472",
+			svc:    "canada_post",
+			locale: "fr-CA",
+			want:   "https://www.canadapost-postescanada.ca/track-reperage/fr#/search?searchFor=7035114477138472",
+		},
+		{
+			name:   "canada post default",
+			in:     "7035114477138472",
+			svc:    "canada_post",
+			locale: "",
+			want:   "https://www.canadapost-postescanada.ca/track-reperage/en#/search?searchFor=7035114477138472",
+		},
+		{
+			name:   "dhl underscore locale",
+			in:     "JVGL0999999990",
+			svc:    "dhl_express",
+			locale: "de_de",
+			want:   "https://www.dhl.com/de-de/home/tracking.html?tracking-id=JVGL0999999990",
+		},
+		{
+			name:   "ups unknown locale",
+			in:     "1Z5R89390357567127",
+			svc:    "ups",
+			locale: "ja-JP",
+			want:   "https://wwwapps.ups.com/WebTracking/track?track=yes&trackNums=1Z5R89390357567127",
+		},
+		{
+			name: "service mobile",
+			in:   "9101026837331000039521",
+			svc:  "usps_91",
+			kind: parcel.URLMobile,
+			want: "usps://track/02/910102683733100003952/1",
+		},
+		{
+			name: "courier mobile with zip",
+			in:   "4201002334249200190132607600833457",
+			svc:  "usps_32V2",
+			kind: parcel.URLMobile,
+			want: "usps://zip/10023/4201002334249200190132607600833457",
+		},
+		{
+			name: "missing placeholder",
+			in:   "7112 3456 7891 2345 6787",
+			svc:  "usps_20",
+			kind: parcel.URLMobile,
+			want: "",
+		},
+		{
+			name: "no mobile template",
+			in:   "986578788855",
+			svc:  "fedex_12",
+			kind: parcel.URLMobile,
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parcel.Track(tt.in)
+			if err != nil {
+				t.Fatalf("parcel.Track() error %v", err)
+			}
+			for _, v := range got {
+				if v.ServiceID != tt.svc {
+					continue
+				}
+				if u := v.URL(tt.locale, tt.kind); u != tt.want {
+					t.Errorf("URL() = %q, want %q", u, tt.want)
+				}
+				return
+			}
+			t.Fatalf("parcel.Track() did not match service %s", tt.svc)
+		})
+	}
+}